@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nicored/csv-chef/csv"
+	"github.com/spf13/cobra"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// version is the version of csv-chef, set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// newRootCmd creates the csv-chef command and its subcommands. For compatibility, running
+// the root command with a configuration and a CSV file is the same as using 'run'
+func newRootCmd() *cobra.Command {
+	var progressInterval time.Duration
+
+	root := &cobra.Command{
+		Use:           "csv-chef [config] [csv]",
+		Short:         "Run recipes of parsers and operations on CSV files",
+		Args:          cobra.RangeArgs(1, 2),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(args[0], csvArg(args), progressInterval)
+		},
+	}
+
+	root.Flags().DurationVar(&progressInterval, "progress", 0, progressUsage)
+
+	root.AddCommand(
+		newRunCmd(),
+		newValidateCmd(),
+		newInferCmd(),
+		newListOperationsCmd(),
+		newListParsersCmd(),
+		newVersionCmd(),
+	)
+
+	return root
+}
+
+// csvArg returns the CSV file given after the configuration, which is optional when the input is a query
+func csvArg(args []string) string {
+	if len(args) > 1 {
+		return args[1]
+	}
+
+	return ""
+}
+
+// progressUsage is the usage of the flag enabling the progress reporting
+const progressUsage = "interval at which the progress is logged to stderr, eg. 10s. Disabled if 0"
+
+func newRunCmd() *cobra.Command {
+	var progressInterval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "run <config> [csv]",
+		Short: "Parse the CSV file, or the result of the query of the input, and run the operations of the configuration",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(args[0], csvArg(args), progressInterval)
+		},
+	}
+
+	cmd.Flags().DurationVar(&progressInterval, "progress", 0, progressUsage)
+
+	return cmd
+}
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate <config> [csv]",
+		Short: "Check the configuration, and the header of the CSV file if given, without processing any data",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return validate(args[0], csvArg(args), cmd.OutOrStdout())
+		},
+	}
+}
+
+func newInferCmd() *cobra.Command {
+	var sampleSize int
+
+	cmd := &cobra.Command{
+		Use:   "infer <csv>",
+		Short: "Generate a starter configuration from the columns of the CSV file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return infer(args[0], sampleSize, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().IntVarP(&sampleSize, "sample", "n", 1000, "number of rows sampled to guess the column types, 0 to read all rows")
+
+	return cmd
+}
+
+func newListOperationsCmd() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "list-operations",
+		Short: "List the available operations and their arguments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(configFile); err != nil {
+				return err
+			}
+
+			for _, op := range csv.Operations() {
+				printArgDef(cmd.OutOrStdout(), op.Name, op.ArgDef)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "configuration whose plugins are loaded")
+
+	return cmd
+}
+
+func newListParsersCmd() *cobra.Command {
+	var configFile string
+
+	cmd := &cobra.Command{
+		Use:   "list-parsers",
+		Short: "List the available parsers and their arguments",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loadConfig(configFile); err != nil {
+				return err
+			}
+
+			for _, parser := range csv.Parsers() {
+				printArgDef(cmd.OutOrStdout(), parser.Name(), parser.ArgDef())
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "configuration whose javascript, lua and plugin parsers are loaded")
+
+	return cmd
+}
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version of csv-chef",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintln(cmd.OutOrStdout(), version)
+		},
+	}
+}
+
+// loadConfig parses the configuration if any, loading the parsers and operations it refers to
+func loadConfig(configFile string) error {
+	if configFile == "" {
+		return nil
+	}
+
+	d := &Data{configFile: configFile}
+	return d.parseConfig()
+}
+
+// printArgDef writes the name of the parser or operation followed by its arguments and their type
+func printArgDef(w io.Writer, name string, argDef csv.ArgDef) {
+	var args []string
+	for arg, typ := range argDef {
+		args = append(args, fmt.Sprintf("%s (%s)", arg, typ))
+	}
+	sort.Strings(args)
+
+	fmt.Fprintf(w, "%s: %s\n", name, strings.Join(args, ", "))
+}
@@ -1,17 +1,30 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/nicored/csv-chef/csv"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 type Config struct {
-	JsParser   []string             `yaml:"jsParsers"`
-	Cols       []*csv.ColDef        `yaml:"cols"`
-	Operations []*csv.OperationConf `yaml:"operations"`
+	Name          string                     `yaml:"name"`
+	JsParser      []string                   `yaml:"jsParsers"`
+	LuaParser     []string                   `yaml:"luaParsers"`
+	Plugins       []string                   `yaml:"plugins"`
+	Input         *csv.InputConf             `yaml:"input"`
+	Inputs        map[string]*csv.NamedInput `yaml:"inputs"`
+	Cols          []*csv.ColDef              `yaml:"cols"`
+	Operations    []*csv.OperationConf       `yaml:"operations"`
+	Notifications []*Notification            `yaml:"notifications"`
+	Metrics       *MetricsConf               `yaml:"metrics"`
+	Timeout       string                     `yaml:"timeout"`
 }
 
 type Data struct {
@@ -23,18 +36,42 @@ type Data struct {
 }
 
 func main() {
-	if len(os.Args) != 3 {
-		logrus.Fatal("expecting 2 arguments, the configuration file and the csv file. eg. csv-chef myconfig.yml mycsv.csv")
+	if err := newRootCmd().Execute(); err != nil {
+		logrus.Fatal(err)
 	}
+}
 
-	d, err := NewData(os.Args[1], os.Args[2])
+// run parses the configuration and runs it on the CSV file. If progressInterval
+// is not 0, the progress of the run is logged at that interval
+func run(configFile string, csvFile string, progressInterval time.Duration) error {
+	d, err := NewData(configFile, csvFile)
 	if err != nil {
-		logrus.Fatal(err)
+		return err
 	}
 
-	if err := d.Do(); err != nil {
-		logrus.Error(err)
+	if progressInterval > 0 {
+		csv.ProgressInterval = progressInterval
+		csv.OnProgress(logProgress)
 	}
+
+	d.serveMetrics()
+
+	return d.Do()
+}
+
+// logProgress logs the progress of the run to stderr
+func logProgress(p csv.Progress) {
+	if p.Operation != "" {
+		logrus.Infof("%d rows read in %s, running operation '%s'", p.RowsRead, p.Elapsed.Round(time.Second), p.Operation)
+		return
+	}
+
+	msg := fmt.Sprintf("%d rows read in %s (%.0f rows/s)", p.RowsRead, p.Elapsed.Round(time.Second), p.RowsPerSec)
+	if p.ETA > 0 {
+		msg += fmt.Sprintf(", %s left", p.ETA.Round(time.Second))
+	}
+
+	logrus.Info(msg)
 }
 
 func NewData(configFile string, csvFile string) (data *Data, err error) {
@@ -47,11 +84,28 @@ func NewData(configFile string, csvFile string) (data *Data, err error) {
 		return
 	}
 
+	csv.OnCompletion(data.notify, data.pushMetrics)
+
 	return
 }
 
 func (d *Data) Do() error {
-	_, err := csv.ReadCsv(d.csvFile, d.ValueDefs, d.Config.Operations)
+	// the run is cancelled on interrupt or when the configured timeout is reached
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if d.Config.Timeout != "" {
+		timeout, err := time.ParseDuration(d.Config.Timeout)
+		if err != nil {
+			return errors.Wrap(err, "invalid timeout")
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	_, err := csv.ReadCsv(ctx, d.csvFile, d.Config.Input, d.ValueDefs, d.Config.Operations)
 	if err != nil {
 		return err
 	}
@@ -66,18 +120,48 @@ func (d *Data) parseConfig() error {
 	}
 
 	conf := &Config{}
-	err = yaml.Unmarshal(content, conf)
+	err = unmarshalConfig(d.configFile, content, conf)
 	if err != nil {
 		return err
 	}
 
 	d.Config = conf
 
+	// the named inputs are loaded along with the main input
+	if len(conf.Inputs) > 0 {
+		if conf.Input == nil {
+			conf.Input = &csv.InputConf{}
+		}
+		conf.Input.Inputs = conf.Inputs
+	}
+
 	if err = d.parseColDefs(); err != nil {
 		return err
 	}
 
-	return d.importJsParsers()
+	if err = d.validateNotifications(); err != nil {
+		return err
+	}
+
+	if err = d.loadPlugins(); err != nil {
+		return err
+	}
+
+	if err = d.importJsParsers(); err != nil {
+		return err
+	}
+
+	return d.importLuaParsers()
+}
+
+func (d *Data) loadPlugins() error {
+	for _, pluginFilepath := range d.Config.Plugins {
+		if err := csv.LoadPlugin(pluginFilepath); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (d *Data) importJsParsers() error {
@@ -95,6 +179,21 @@ func (d *Data) importJsParsers() error {
 	return nil
 }
 
+func (d *Data) importLuaParsers() error {
+	for _, luaFilepath := range d.Config.LuaParser {
+		parser, err := csv.NewLuaParser(luaFilepath)
+		if err != nil {
+			return err
+		}
+
+		if err = csv.AddParsers(parser); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (d *Data) parseColDefs() (err error) {
 	def := csv.ValueDefs{}
 
@@ -104,4 +203,4 @@ func (d *Data) parseColDefs() (err error) {
 
 	d.ValueDefs = def
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// envVarRegexp matches the environment variables referenced in the configuration
+// as ${NAME}, or ${NAME:-default} to fall back to a default value. References escaped
+// as $${NAME} are kept as ${NAME}, eg. for the named groups of regexReplace
+var envVarRegexp = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces the environment variables referenced in the string values of the decoded
+// configuration by their value. Keys are left as they are. An error is returned if a variable
+// without default value is not set
+func expandEnv(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+
+		expanded, err := expandEnvString(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(expanded)
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		// values held by interfaces are not settable, they are expanded on a copy
+		if v.Kind() == reflect.Interface && v.CanSet() {
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			if err := expandEnv(elem); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+
+		return expandEnv(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := expandEnv(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandEnv(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map values are not settable, they are expanded on a copy
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := expandEnv(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	}
+
+	return nil
+}
+
+// expandEnvString replaces the environment variables referenced in the value by their value
+func expandEnvString(value string) (string, error) {
+	var err error
+
+	expanded := envVarRegexp.ReplaceAllStringFunc(value, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+
+		m := envVarRegexp.FindStringSubmatch(ref)
+
+		if val, ok := os.LookupEnv(m[1]); ok {
+			return val
+		}
+
+		if m[2] != "" {
+			return m[3]
+		}
+
+		if err == nil {
+			err = fmt.Errorf("environment variable '%s' is not set", m[1])
+		}
+
+		return ref
+	})
+
+	return expanded, err
+}
+
+// unmarshalConfig decodes the configuration according to the extension of its file, either
+// YAML (default), JSON (.json) or TOML (.toml), and expands the environment variables of its
+// values. JSON and TOML configurations are converted to YAML so that all formats share the same keys
+func unmarshalConfig(filename string, content []byte, conf *Config) error {
+	var raw interface{}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return err
+		}
+	case ".toml":
+		var table map[string]interface{}
+		if err := toml.Unmarshal(content, &table); err != nil {
+			return err
+		}
+		raw = table
+	}
+
+	// YAML is decoded as is, a round trip through interface{} would turn its scalars
+	// such as 'n' or 'on' into booleans
+	if raw != nil {
+		var err error
+		if content, err = yaml.Marshal(raw); err != nil {
+			return err
+		}
+	}
+
+	if err := yaml.Unmarshal(content, conf); err != nil {
+		return err
+	}
+
+	return expandEnv(reflect.ValueOf(conf))
+}
@@ -9,10 +9,11 @@ type FuncArgs map[string]interface{}
 // ParserArg is the argument configuration for the parser as defined
 // in the loaded configuration.
 type ParserArg struct {
-	Value  string      `yaml:"value"`
-	Values []ParserArg `yaml:"values"`
-	Col    string      `yaml:"col"`
-	Cols   []string    `yaml:"cols"`
+	Value  string            `yaml:"value"`
+	Values []ParserArg       `yaml:"values"`
+	Col    string            `yaml:"col"`
+	Cols   []string          `yaml:"cols"`
+	Map    map[string]string `yaml:"map"` // key to value mapping, eg. to translate codes
 }
 
 // ArgDef maps the argument name to its expected type from the parser
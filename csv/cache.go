@@ -0,0 +1,31 @@
+package csv
+
+import "sync"
+
+// compileCache holds the values compiled by the parsers from their source, eg. regular
+// expressions or templates, mapped by source, so that they are compiled once rather than for each row
+type compileCache[T any] struct {
+	compile func(src string) (T, error)
+	values  sync.Map
+}
+
+// newCompileCache returns a cache of the values compiled by compile
+func newCompileCache[T any](compile func(src string) (T, error)) *compileCache[T] {
+	return &compileCache[T]{compile: compile}
+}
+
+// get returns the compiled value of the source, compiling it if needed. Sources failing
+// to compile are not cached
+func (c *compileCache[T]) get(src string) (T, error) {
+	if v, ok := c.values.Load(src); ok {
+		return v.(T), nil
+	}
+
+	v, err := c.compile(src)
+	if err != nil {
+		return v, err
+	}
+
+	c.values.Store(src, v)
+	return v, nil
+}
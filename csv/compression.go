@@ -0,0 +1,77 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"strings"
+)
+
+// Supported compression formats
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompressReader detects whether the data in r is gzip or zstd compressed from its
+// magic number, and wraps r with the matching decompressor. The returned closer
+// releases the decompressor resources
+func decompressReader(r io.Reader) (io.Reader, io.Closer, error) {
+	br := bufio.NewReader(r)
+
+	// an error means less than 4 bytes are available, which is handled by the csv reader
+	magic, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gr, gr, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.IOReadCloser(), nil
+	}
+
+	return br, nopCloser{}, nil
+}
+
+// compressionFromFilename returns the compression format matching the file extension
+func compressionFromFilename(filename string) string {
+	switch {
+	case strings.HasSuffix(filename, ".gz"):
+		return CompressionGzip
+	case strings.HasSuffix(filename, ".zst"):
+		return CompressionZstd
+	}
+
+	return CompressionNone
+}
+
+// compressWriter wraps w with a compressor for the given format.
+// The returned writer must be closed to flush the compressor
+func compressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "", CompressionNone:
+		return nopCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		return zstd.NewWriter(w)
+	}
+
+	return nil, fmt.Errorf("unsupported compression '%s', expected '%s', '%s' or '%s'", compression, CompressionNone, CompressionGzip, CompressionZstd)
+}
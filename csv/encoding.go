@@ -0,0 +1,66 @@
+package csv
+
+import (
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"strings"
+)
+
+// lookupEncoding returns the character encoding from its name, eg. 'windows-1252',
+// 'iso-8859-1' or 'utf-16le'. A nil encoding is returned for UTF-8
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "utf-8" || name == "utf8" {
+		return nil, nil
+	}
+
+	if name == "utf-16" {
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding '%s'", name)
+	}
+
+	return enc, nil
+}
+
+// decodeReader wraps r with a decoder converting the given encoding to UTF-8.
+// A byte order mark at the start of the data overrides the given encoding
+func decodeReader(r io.Reader, name string) (io.Reader, error) {
+	enc, err := lookupEncoding(name)
+	if err != nil || enc == nil {
+		return r, err
+	}
+
+	return transform.NewReader(r, unicode.BOMOverride(enc.NewDecoder())), nil
+}
+
+// nopCloser is returned when the output does not need to be encoded
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// encodeWriter wraps w with an encoder converting UTF-8 to the given encoding.
+// The returned writer must be closed to flush the encoder
+func encodeWriter(w io.Writer, name string) (io.WriteCloser, error) {
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if enc == nil {
+		return nopCloser{w}, nil
+	}
+
+	return transform.NewWriter(w, enc.NewEncoder()), nil
+}
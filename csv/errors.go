@@ -0,0 +1,38 @@
+package csv
+
+import "fmt"
+
+// RowError is the error returned when a row fails to be parsed or an operation fails.
+// It exposes where the failure happened so that it can be handled or reported without
+// parsing the error message
+type RowError struct {
+	RowIndex  int    // index of the row in the file, the header being row 0. -1 if the error is not tied to a row
+	Column    string // name of the column being parsed, if any
+	Parser    string // name of the parser which failed, if any
+	Operation string // name of the operation which failed, if any
+	Err       error  // the underlying cause
+}
+
+// Error returns the error message along with its context
+func (e *RowError) Error() string {
+	switch {
+	case e.Operation != "":
+		return fmt.Sprintf("error running operation '%s': %s", e.Operation, e.Err)
+	case e.Parser != "":
+		return fmt.Sprintf("error running parser '%s' in column '%s' in row %d: %s", e.Parser, e.Column, e.RowIndex, e.Err)
+	case e.Column != "":
+		return fmt.Sprintf("error in column '%s' in row %d: %s", e.Column, e.RowIndex, e.Err)
+	}
+
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause, so that errors.Is and errors.As can be used on it
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns the underlying cause, for use with github.com/pkg/errors
+func (e *RowError) Cause() error {
+	return e.Err
+}
@@ -0,0 +1,42 @@
+package csv
+
+import "time"
+
+// RunSummary describes a completed run and is passed to the completion handlers
+type RunSummary struct {
+	File         string        // the CSV file that was processed, empty when processing a reader
+	Start        time.Time     // when the run started
+	Duration     time.Duration // how long the run took
+	RowsRead     int           // number of rows read from the CSV, header and rejected rows excluded
+	RowsRejected int           // number of rows which failed to parse and were left out
+	Operations   int           // number of operations executed
+	Err          error         // the error that stopped the run, nil on success
+
+	progress *progressTracker // reports the progress while the run is in progress, nil if not reported
+}
+
+// CompletionHandler is a function called at the end of every run, whether it succeeded or not
+type CompletionHandler func(summary RunSummary)
+
+// completionHandlers is the list of handlers called when a run completes
+var completionHandlers []CompletionHandler
+
+// OnCompletion registers handlers called at the end of every run
+func OnCompletion(handlers ...CompletionHandler) {
+	completionHandlers = append(completionHandlers, handlers...)
+}
+
+// notifyCompletion calls all registered completion handlers with the run summary
+func notifyCompletion(summary RunSummary) {
+	for _, handler := range completionHandlers {
+		handler(summary)
+	}
+}
+
+// complete finalises the run summary and notifies the completion handlers
+func complete(summary *RunSummary, err error) {
+	summary.progress.stop()
+	summary.Duration = time.Since(summary.Start)
+	summary.Err = err
+	notifyCompletion(*summary)
+}
@@ -0,0 +1,81 @@
+package csv
+
+import (
+	"fmt"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/pkg/errors"
+)
+
+// compileExpr compiles an expression evaluated against the values of a row, see
+// https://expr-lang.org for its syntax. Eg. 'price * quantity' or 'country == "FR" ? "EU" : "other"'
+func compileExpr(code string) (*vm.Program, error) {
+	program, err := expr.Compile(code)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expression '%s'", code)
+	}
+
+	return program, nil
+}
+
+// evalExpr evaluates the compiled expression against the values of the row and
+// returns its result as a string
+func evalExpr(program *vm.Program, row Row, defs ValueDefs) (string, error) {
+	out, err := expr.Run(program, exprEnv(row, defs))
+	if err != nil {
+		return "", err
+	}
+
+	if out == nil {
+		return "", nil
+	}
+
+	return fmt.Sprint(out), nil
+}
+
+// exprEnv returns the values of the row typed according to their column definition,
+// so that they can be used in expressions. Empty dates and timestamps are nil, as well as the
+// values left empty, eg. the columns of the missing side of a join
+func exprEnv(row Row, defs ValueDefs) map[string]interface{} {
+	env := map[string]interface{}{}
+
+	for col, val := range row {
+		if val == nil {
+			continue
+		}
+
+		def, ok := defs[col]
+		if !ok {
+			env[col] = val.ValStr()
+			continue
+		}
+
+		switch def.Type {
+		case TypInt:
+			env[col] = nil
+			if v := val.ValInt(); v != nil {
+				env[col] = *v
+			}
+		case TypFloat:
+			env[col] = nil
+			if v := val.ValFloat(); v != nil {
+				env[col] = *v
+			}
+		case TypBool:
+			env[col] = nil
+			if v := val.ValBool(); v != nil {
+				env[col] = *v
+			}
+		case TypDate, TypTime:
+			if t := val.ValTime(); t != nil {
+				env[col] = *t
+			} else {
+				env[col] = nil
+			}
+		default:
+			env[col] = val.ValStr()
+		}
+	}
+
+	return env
+}
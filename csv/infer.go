@@ -0,0 +1,126 @@
+package csv
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InferDefs reads up to sampleSize rows of the CSV data from in and guesses the type of
+// each column from its values. The definitions are returned in the order of the header
+func InferDefs(in io.Reader, conf *InputConf, sampleSize int) ([]*ColDef, error) {
+	if conf == nil {
+		conf = &InputConf{}
+	}
+
+	csvR, decompressor, err := newCsvReader(in, conf, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decompressor.Close()
+
+	header, err := csvR.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]string, len(header))
+	for i := 0; sampleSize <= 0 || i < sampleSize; i++ {
+		rec, err := csvR.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for ci, cell := range rec {
+			if ci >= len(values) {
+				break
+			}
+
+			// empty values are replaced by defaults, they don't tell anything about the type
+			if cell = strings.TrimSpace(cell); cell != "" {
+				values[ci] = append(values[ci], cell)
+			}
+		}
+	}
+
+	var defs []*ColDef
+	for i, name := range header {
+		typ, layout := inferType(values[i])
+		defs = append(defs, &ColDef{Name: strings.TrimSpace(name), Type: typ, Layout: layout})
+	}
+
+	return defs, nil
+}
+
+// inferType returns the most specific type all the values can be parsed to,
+// and the layout of the values for dates and timestamps
+func inferType(values []string) (string, string) {
+	if len(values) == 0 {
+		return TypStr, ""
+	}
+
+	if allValues(values, func(v string) bool { _, err := strconv.Atoi(v); return err == nil }) {
+		return TypInt, ""
+	}
+
+	if allValues(values, func(v string) bool { _, err := strconv.ParseFloat(v, 64); return err == nil }) {
+		return TypFloat, ""
+	}
+
+	if allValues(values, func(v string) bool { _, ok := strBool[strings.ToLower(v)]; return ok }) {
+		return TypBool, ""
+	}
+
+	for _, layout := range timeLayouts {
+		if !allValues(values, func(v string) bool { _, err := time.Parse(layout, v); return err == nil }) {
+			continue
+		}
+
+		if strings.Contains(layout, "15:04") {
+			return TypTime, layout
+		}
+
+		return TypDate, layout
+	}
+
+	// the values may be in different formats, which are then detected when parsing them
+	typ := TypDate
+	for _, v := range values {
+		layout, ok := timeLayout(v)
+		if !ok {
+			return TypStr, ""
+		}
+
+		if strings.Contains(layout, "15:04") {
+			typ = TypTime
+		}
+	}
+
+	return typ, ""
+}
+
+// timeLayout returns the first of the common layouts the value can be parsed with
+func timeLayout(v string) (string, bool) {
+	for _, layout := range timeLayouts {
+		if _, err := time.Parse(layout, v); err == nil {
+			return layout, true
+		}
+	}
+
+	return "", false
+}
+
+// allValues returns whether all the values satisfy the condition
+func allValues(values []string, cond func(v string) bool) bool {
+	for _, v := range values {
+		if !cond(v) {
+			return false
+		}
+	}
+
+	return true
+}
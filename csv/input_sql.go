@@ -0,0 +1,108 @@
+package csv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// validateQuery checks that the driver of the query input is registered, and that a DSN is provided
+func validateQuery(conf *InputConf) error {
+	if conf.Query == "" {
+		if conf.Driver != "" || conf.DSN != "" {
+			return errors.New("the driver and dsn of the input require a query")
+		}
+
+		return nil
+	}
+
+	drivers := sql.Drivers()
+	sort.Strings(drivers)
+
+	i := sort.SearchStrings(drivers, conf.Driver)
+	if i == len(drivers) || drivers[i] != conf.Driver {
+		return fmt.Errorf("unsupported driver '%s', must be one of '%s'", conf.Driver, strings.Join(drivers, "', '"))
+	}
+
+	if conf.DSN == "" {
+		return errors.New("the dsn of the input is required with a query")
+	}
+
+	return nil
+}
+
+// readQuery runs the query of the input configuration and returns the rows of its result set
+// as well as its columns
+func readQuery(ctx context.Context, conf *InputConf, defs ValueDefs, rejects *[]rejectedRow, summary *RunSummary) ([]Row, []string, error) {
+	if err := validateQuery(conf); err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open(conf.Driver, conf.DSN)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error opening database")
+	}
+	defer db.Close()
+
+	result, err := db.QueryContext(ctx, conf.Query)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error running query")
+	}
+	defer result.Close()
+
+	cols, err := result.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return readRecords(ctx, &sqlReader{rows: result, header: cols}, "", conf, defs, rejects, summary)
+}
+
+// sqlReader reads the rows of a result set as records, NULL values being read as empty values
+type sqlReader struct {
+	rows   *sql.Rows
+	header []string
+}
+
+// Read returns the columns first, and then the values of the rows
+func (r *sqlReader) Read() ([]string, error) {
+	if r.header != nil {
+		header := r.header
+		r.header = nil
+		return header, nil
+	}
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	cols, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	rec := make([]string, len(cols))
+	for i, val := range vals {
+		rec[i] = val.String
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,98 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// NamedInput is an additional file loaded into the state of the same name before running the
+// operations, eg. to be joined with the rows of the main input
+type NamedInput struct {
+	File  string     `yaml:"file"`  // local path or remote URI of the file
+	Input *InputConf `yaml:"input"` // configuration of the input, defaults apply if not set
+	Cols  []*ColDef  `yaml:"cols"`  // definitions of the columns of the file
+}
+
+// defs returns the definitions of the columns of the named input mapped by name
+func (ni *NamedInput) defs() ValueDefs {
+	defs := ValueDefs{}
+	for _, col := range ni.Cols {
+		defs[col.Name] = col
+	}
+
+	return withSourceCol(defs, ni.conf())
+}
+
+// conf returns the configuration of the named input
+func (ni *NamedInput) conf() *InputConf {
+	if ni.Input == nil {
+		return &InputConf{}
+	}
+
+	return ni.Input
+}
+
+// validateInputs checks the file, the input configuration and the columns of each named input
+func validateInputs(inputs map[string]*NamedInput) []error {
+	var names []string
+	for name := range inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		ni := inputs[name]
+		if ni == nil || ni.File == "" {
+			errs = append(errs, fmt.Errorf("file of input '%s' is required", name))
+			continue
+		}
+
+		if len(ni.Cols) == 0 {
+			errs = append(errs, fmt.Errorf("input '%s' has no cols", name))
+		}
+
+		var inputErrs []error
+		inputErrs = append(inputErrs, validateInputConf(ni.conf())...)
+
+		defs := ni.defs()
+		for _, colName := range sortedCols(defs) {
+			inputErrs = append(inputErrs, validateColDef(colName, defs[colName], defs)...)
+		}
+
+		for _, err := range inputErrs {
+			errs = append(errs, errors.Wrapf(err, "input '%s'", name))
+		}
+	}
+
+	return errs
+}
+
+// loadInputs reads and parses the files of the named inputs, and returns their rows as states
+// mapped by the name of the input
+func loadInputs(ctx context.Context, inputs map[string]*NamedInput) (map[string]*OpState, error) {
+	if errs := validateInputs(inputs); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	states := map[string]*OpState{}
+	for name, ni := range inputs {
+		conf, defs := ni.conf(), ni.defs()
+
+		var rejects []rejectedRow
+		rows, _, err := readFile(ctx, ni.File, conf, defs, &rejects, &RunSummary{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading input '%s'", name)
+		}
+
+		if err := handleRejects(ctx, conf, rejects); err != nil {
+			return nil, errors.Wrapf(err, "error reading input '%s'", name)
+		}
+
+		states[name] = &OpState{Rows: rows, Defs: defs}
+	}
+
+	return states, nil
+}
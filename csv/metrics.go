@@ -0,0 +1,54 @@
+package csv
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// Error types used to label the errors counter
+const (
+	ErrTypRead      = "read"
+	ErrTypValue     = "value"
+	ErrTypParser    = "parser"
+	ErrTypOperation = "operation"
+)
+
+var (
+	rowsProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "csvchef",
+		Name:      "rows_processed_total",
+		Help:      "Number of rows read and parsed from CSV files.",
+	})
+
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "csvchef",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of the operations executed, by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, []string{"operation"})
+
+	errorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "csvchef",
+		Name:      "errors_total",
+		Help:      "Number of errors, by type.",
+	}, []string{"type"})
+)
+
+// Metrics is the registry holding all csv-chef metrics, ready to be exposed
+// through a /metrics endpoint or pushed to a Prometheus push gateway
+var Metrics = prometheus.NewRegistry()
+
+func init() {
+	Metrics.MustRegister(rowsProcessed, operationDuration, errorsTotal)
+}
+
+// countError increments the errors counter for the given type and returns the error unchanged
+func countError(typ string, err error) error {
+	errorsTotal.WithLabelValues(typ).Inc()
+	return err
+}
+
+// observeOperation records the duration of the named operation since start
+func observeOperation(operation string, start time.Time) {
+	operationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -1,13 +1,37 @@
 package csv
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"sort"
 )
 
-type OpFunc func(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error)
+// Error policies applied when reading rows or delivering them to external services
+const (
+	OnErrorAbort   = "abort"   // the run stops and returns the error
+	OnErrorSkip    = "skip"    // the failing row is left out and the run carries on
+	OnErrorCollect = "collect" // failing rows are left out and reported together once all rows are read
+)
+
+type OpFunc func(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error)
 
 var operations = map[string]Operation{}
 
+// Operations returns all available operations sorted by name
+func Operations() []Operation {
+	var ops []Operation
+	for _, op := range operations {
+		ops = append(ops, op)
+	}
+
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Name < ops[j].Name
+	})
+
+	return ops
+}
+
 func AddOperations(newOps ...Operation) error {
 	for _, op := range newOps {
 		if _, ok := operations[op.Name]; ok {
@@ -41,8 +65,14 @@ type Operation struct {
 	ArgDef ArgDef
 }
 
-func (op *Operation) Execute(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
-	return op.OpFunc(rows, defs, args)
+func (op *Operation) Execute(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	return op.OpFunc(ctx, env, rows, defs, args)
+}
+
+// OpEnv is the environment of the run the operations are executed in
+type OpEnv struct {
+	Out    io.Writer           // where operations printing their output write to
+	States map[string]*OpState // the states kept so far, mapped by operation name
 }
 
 type OpArg struct {
@@ -1,12 +1,17 @@
 package csv
 
 import (
+	"bufio"
+	"context"
 	"crypto/md5"
 	gocsv "encoding/csv"
 	"encoding/hex"
+	"fmt"
 	"github.com/pkg/errors"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"reflect"
 	"sort"
 	"strconv"
@@ -23,6 +28,37 @@ func init() {
 		findDupesOp,
 		mergeDupesOp,
 		md5FileOp,
+		toKafkaOp,
+		webhookOp,
+		joinOp,
+		groupByOp,
+		dedupeOp,
+		limitOp,
+		tailOp,
+		sampleOp,
+		selectColumnsOp,
+		dropColumnsOp,
+		addColumnOp,
+		unionOp,
+		diffOp,
+		describeOp,
+		fillDownOp,
+		splitFilesOp,
+		rowCountOp,
+		assertCountOp,
+		windowOp,
+		lookupOp,
+		antiJoinOp,
+		explodeOp,
+		mergeColumnsOp,
+		toJsonOp,
+		toExcelOp,
+		toHtmlOp,
+		toSqliteOp,
+		toPostgresOp,
+		httpPostOp,
+		toFixedWidthOp,
+		toTemplateOp,
 	)
 	if err != nil {
 		panic(err)
@@ -32,27 +68,138 @@ func init() {
 var printOperation = Operation{
 	Name:   "print",
 	OpFunc: opPrint,
-	ArgDef: ArgDef{"cols": reflect.TypeOf([]string{})},
+	ArgDef: ArgDef{
+		"cols":      reflect.TypeOf([]string{}),
+		"delimiter": reflect.TypeOf(""),
+		"encoding":  reflect.TypeOf(""),
+		"quoteAll":  reflect.TypeOf(true),
+		"crlf":      reflect.TypeOf(true),
+		"header":    reflect.TypeOf(true),
+		"format":    reflect.TypeOf(""),
+		"maxWidth":  reflect.TypeOf(1),
+		"maxRows":   reflect.TypeOf(1),
+	},
 }
 
-func opPrint(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
-	colsI, ok := args["cols"]
-	if !ok {
-		return nil, nil, errors.New("cols argument not provided")
+// recordWriter writes CSV records, as done by encoding/csv writers
+type recordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// quoteAllWriter is a recordWriter quoting all the fields, which encoding/csv writers
+// only do when needed
+type quoteAllWriter struct {
+	w     *bufio.Writer
+	comma rune
+	crlf  bool
+	err   error
+}
+
+// Write writes a record, quoting all its fields
+func (w *quoteAllWriter) Write(record []string) error {
+	if w.err != nil {
+		return w.err
 	}
 
-	cols := colsI.([]string)
+	for i, field := range record {
+		if i > 0 {
+			w.w.WriteRune(w.comma)
+		}
+
+		w.w.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`)
+	}
+
+	if w.crlf {
+		_, w.err = w.w.WriteString("\r\n")
+	} else {
+		_, w.err = w.w.WriteString("\n")
+	}
+
+	return w.err
+}
+
+// Flush writes the buffered records to the underlying writer
+func (w *quoteAllWriter) Flush() {
+	if err := w.w.Flush(); err != nil && w.err == nil {
+		w.err = err
+	}
+}
+
+// Error returns the first error which occurred while writing or flushing
+func (w *quoteAllWriter) Error() error {
+	return w.err
+}
+
+// newCsvWriter creates a CSV writer configured from the optional writer arguments
+// of the operation (delimiter, encoding, quoteAll, crlf). The returned closer must be
+// closed once the writer is flushed
+func newCsvWriter(out io.Writer, args FuncArgs) (recordWriter, io.Closer, error) {
+	delimiterStr, err := argStringOpt(args, "delimiter", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delimiter, err := parseDelimiter(delimiterStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encodingName, err := argStringOpt(args, "encoding", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quoteAll, err := argBoolOpt(args, "quoteAll", false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	crlf, err := argBoolOpt(args, "crlf", false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enc, err := encodeWriter(out, encodingName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if quoteAll {
+		return &quoteAllWriter{w: bufio.NewWriter(enc), comma: delimiter, crlf: crlf}, enc, nil
+	}
+
+	w := gocsv.NewWriter(enc)
+	w.Comma = delimiter
+	w.UseCRLF = crlf
+
+	return w, enc, nil
+}
+
+// writeRecords writes the columns of the rows as CSV, configured from the optional writer
+// arguments of the operation (delimiter, encoding, quoteAll, crlf, header)
+func writeRecords(out io.Writer, rows []Row, cols []string, args FuncArgs) error {
+	withHeader, err := argBoolOpt(args, "header", true)
+	if err != nil {
+		return err
+	}
 
-	w := gocsv.NewWriter(os.Stdout)
+	w, enc, err := newCsvWriter(out, args)
+	if err != nil {
+		return err
+	}
 
 	// printing header
-	var header []string
-	for _, h := range cols {
-		header = append(header, h)
+	if withHeader {
+		var header []string
+		for _, h := range cols {
+			header = append(header, h)
+		}
+		w.Write(header)
 	}
-	w.Write(header)
 
-	for i, r := range *rows {
+	for i, r := range rows {
 		var output []string
 		for _, col := range cols {
 			output = append(output, r[col].ValStr())
@@ -65,16 +212,55 @@ func opPrint(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, erro
 	}
 
 	w.Flush()
-	return nil, nil, nil
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func opPrint(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	colsI, ok := args["cols"]
+	if !ok {
+		return nil, nil, errors.New("cols argument not provided")
+	}
+
+	cols := colsI.([]string)
+
+	format, err := argStringOpt(args, "format", PrintCsv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch format {
+	case PrintCsv:
+		return nil, nil, writeRecords(env.Out, *rows, cols, args)
+	case PrintTable:
+		return nil, nil, writeTable(env.Out, *rows, defs, cols, args)
+	}
+
+	return nil, nil, fmt.Errorf("format must either be '%s' or '%s'", PrintCsv, PrintTable)
 }
 
 var toFileOperation = Operation{
 	Name:   "toFile",
 	OpFunc: opToFile,
-	ArgDef: ArgDef{"filename": reflect.TypeOf(""), "cols": reflect.TypeOf([]string{})},
+	ArgDef: ArgDef{
+		"filename":       reflect.TypeOf(""),
+		"cols":           reflect.TypeOf([]string{}),
+		"delimiter":      reflect.TypeOf(""),
+		"encoding":       reflect.TypeOf(""),
+		"quoteAll":       reflect.TypeOf(true),
+		"crlf":           reflect.TypeOf(true),
+		"header":         reflect.TypeOf(true),
+		"compression":    reflect.TypeOf(""),
+		"append":         reflect.TypeOf(true),
+		"atomic":         reflect.TypeOf(true),
+		"maxRowsPerFile": reflect.TypeOf(1),
+	},
 }
 
-func opToFile(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opToFile(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	colsI, ok := args["cols"]
 	if !ok {
 		return nil, nil, errors.New("cols argument not provided")
@@ -89,44 +275,149 @@ func opToFile(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, err
 
 	fileName := val.(string)
 
-	wf, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE, 0777)
+	maxRows, err := argIntOpt(args, "maxRowsPerFile", 0)
 	if err != nil {
 		return nil, nil, err
 	}
-	defer wf.Close()
 
-	w := gocsv.NewWriter(wf)
+	if maxRows <= 0 {
+		return nil, nil, writeFile(ctx, env, fileName, *rows, cols, args)
+	}
 
-	// printing header
-	var header []string
-	for _, h := range cols {
-		header = append(header, h)
+	if fileName == StdStream {
+		return nil, nil, errors.New("maxRowsPerFile cannot be used when writing to '-'")
 	}
-	w.Write(header)
 
-	for i, r := range *rows {
-		var output []string
-		for _, col := range cols {
-			output = append(output, r[col].ValStr())
+	// the rows are split into numbered files, eg. out_0001.csv, out_0002.csv, the first one
+	// being written even if there are no rows
+	for i := 0; i == 0 || i*maxRows < len(*rows); i++ {
+		end := (i + 1) * maxRows
+		if end > len(*rows) {
+			end = len(*rows)
 		}
-		w.Write(output)
 
-		if i > 1 && i%100 == 0 {
-			w.Flush()
+		if err := writeFile(ctx, env, chunkFileName(fileName, i+1), (*rows)[i*maxRows:end], cols, args); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	w.Flush()
 	return nil, nil, nil
 }
 
+// chunkFileName returns the name of the numbered file, the number being inserted before
+// the extensions of the file, eg. out_0001.csv.gz
+func chunkFileName(fileName string, n int) string {
+	dir, base := path.Split(fileName)
+
+	ext := ""
+	if i := strings.Index(base, "."); i > 0 {
+		base, ext = base[:i], base[i:]
+	}
+
+	return fmt.Sprintf("%s%s_%04d%s", dir, base, n, ext)
+}
+
+// writeFile writes the columns of the rows to the file, configured from the optional writer
+// arguments of the operation (delimiter, encoding, quoteAll, crlf, header, compression, append, atomic). '-' writes to the output of the run
+func writeFile(ctx context.Context, env *OpEnv, fileName string, rows []Row, cols []string, args FuncArgs) error {
+	appendMode, err := argBoolOpt(args, "append", false)
+	if err != nil {
+		return err
+	}
+
+	// the header is only written once when appending to a file
+	if appendMode {
+		if info, err := os.Stat(fileName); err == nil && info.Size() > 0 {
+			headerArgs := FuncArgs{}
+			for name, val := range args {
+				headerArgs[name] = val
+			}
+			headerArgs["header"] = false
+			args = headerArgs
+		}
+	}
+
+	return writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		return writeRecords(out, rows, cols, args)
+	})
+}
+
+// writeOutput creates the file, compressed according to the optional compression argument of the
+// operation, and writes to it with the write function. '-' writes to the output of the run.
+// Local files can be appended to, or written atomically, according to the optional append and
+// atomic arguments
+func writeOutput(ctx context.Context, env *OpEnv, fileName string, args FuncArgs, write func(out io.Writer) error) error {
+	// the compression is inferred from the file extension if not provided
+	compression, err := argStringOpt(args, "compression", compressionFromFilename(fileName))
+	if err != nil {
+		return err
+	}
+
+	appendMode, err := argBoolOpt(args, "append", false)
+	if err != nil {
+		return err
+	}
+
+	atomic, err := argBoolOpt(args, "atomic", false)
+	if err != nil {
+		return err
+	}
+
+	if appendMode && atomic {
+		return errors.New("append and atomic cannot be used together")
+	}
+
+	if (appendMode || atomic) && !isLocal(fileName) {
+		return fmt.Errorf("append and atomic are only supported for local files, got '%s'", fileName)
+	}
+
+	// '-' writes to the output of the run, stdout when running from the command line
+	var wf io.WriteCloser = nopCloser{env.Out}
+	var af *atomicFile
+
+	switch {
+	case appendMode:
+		wf, err = appendFile(fileName)
+	case atomic:
+		af, err = createAtomicFile(fileName)
+		wf = af
+	case fileName != StdStream:
+		wf, err = createFile(ctx, fileName)
+	}
+	if err != nil {
+		return err
+	}
+
+	// abort discards the file after a failure, leaving the file untouched when written atomically
+	// and publishing no partial remote file
+	abort := func(err error) error {
+		return abortFile(wf, err)
+	}
+
+	cw, err := compressWriter(wf, compression)
+	if err != nil {
+		return abort(err)
+	}
+
+	if err := write(cw); err != nil {
+		return abort(err)
+	}
+
+	if err := cw.Close(); err != nil {
+		return abort(err)
+	}
+
+	// closing explicitly as remote files are only uploaded on close
+	return wf.Close()
+}
+
 var sortOperation = Operation{
 	Name:   "sort",
 	OpFunc: opSort,
 	ArgDef: ArgDef{"cols": reflect.TypeOf([]string{}), "order": reflect.TypeOf([]string{})},
 }
 
-func opSort(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opSort(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	colsI, ok := args["cols"]
 	if !ok {
 		return nil, nil, errors.New("cols argument not provided")
@@ -142,64 +433,120 @@ func opSort(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error
 	if len(order) != len(cols) {
 		return nil, nil, errors.New("number of items in 'order' must be equal to number of items in 'cols'")
 	}
+
+	for colI := range order {
+		if order[colI] != "desc" && order[colI] != "asc" {
+			order[colI] = "asc"
+		}
+	}
+
 	sort.Slice(*rows,
 		func(i, j int) bool {
-			for colI, col := range cols {
-				colDef := defs[col]
+			return rowLess((*rows)[i], (*rows)[j], defs, cols, order)
+		})
+
+	return nil, nil, nil
+}
+
+// rowLess tells whether row a comes before row b when sorting by the given columns
+// in the given order (asc or desc). Booleans are sorted false first, empty values first
+func rowLess(a, b Row, defs ValueDefs, cols []string, order []string) bool {
+	for colI, col := range cols {
+		colDef := defs[col]
+
+		if colDef.Type == TypStr {
+			if order[colI] == "asc" {
+				if a[col].ValStr() < b[col].ValStr() {
+					return true
+				}
 
-				if order[colI] != "desc" && order[colI] != "asc" {
-					order[colI] = "asc"
+				if a[col].ValStr() > b[col].ValStr() {
+					return false
 				}
+			}
 
-				if colDef.Type == TypStr {
-					if order[colI] == "asc" {
-						if (*rows)[i][col].ValStr() < (*rows)[j][col].ValStr() {
-							return true
-						}
-
-						if (*rows)[i][col].ValStr() > (*rows)[j][col].ValStr() {
-							return false
-						}
-					}
-
-					if order[colI] == "desc" {
-						if (*rows)[i][col].ValStr() > (*rows)[j][col].ValStr() {
-							return true
-						}
-
-						if (*rows)[i][col].ValStr() < (*rows)[j][col].ValStr() {
-							return false
-						}
-					}
+			if order[colI] == "desc" {
+				if a[col].ValStr() > b[col].ValStr() {
+					return true
 				}
 
-				if colDef.Type == TypFloat || colDef.Type == TypInt {
-					if order[colI] == "asc" {
-						if *(*rows)[i][col].ValFloat() < *(*rows)[j][col].ValFloat() {
-							return true
-						}
-
-						if *(*rows)[i][col].ValFloat() > *(*rows)[j][col].ValFloat() {
-							return false
-						}
-					}
-
-					if order[colI] == "desc" {
-						if *(*rows)[i][col].ValFloat() > *(*rows)[j][col].ValFloat() {
-							return true
-						}
-
-						if *(*rows)[i][col].ValFloat() < *(*rows)[j][col].ValFloat() {
-							return false
-						}
-					}
+				if a[col].ValStr() < b[col].ValStr() {
+					return false
 				}
 			}
+		}
 
-			return false
-		})
+		if colDef.Type == TypFloat || colDef.Type == TypInt || colDef.Type == TypBool {
+			cmp := compareFloat(a[col].ValFloat(), b[col].ValFloat())
+			if colDef.Type == TypBool {
+				cmp = compareBool(a[col].ValBool(), b[col].ValBool())
+			}
+			if order[colI] == "desc" {
+				cmp = -cmp
+			}
 
-	return nil, nil, nil
+			if cmp < 0 {
+				return true
+			}
+
+			if cmp > 0 {
+				return false
+			}
+		}
+
+		if isTimeType(colDef.Type) {
+			cmp := compareTime(a[col].ValTime(), b[col].ValTime())
+			if order[colI] == "desc" {
+				cmp = -cmp
+			}
+
+			if cmp < 0 {
+				return true
+			}
+
+			if cmp > 0 {
+				return false
+			}
+		}
+	}
+
+	return false
+}
+
+// compareFloat compares two numbers, empty ones being lower than the others
+func compareFloat(a, b *float64) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+
+	return 0
+}
+
+// compareBool compares two booleans, false being lower than true and empty ones lower than both
+func compareBool(a, b *bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case !*a && *b:
+		return -1
+	case *a && !*b:
+		return 1
+	}
+
+	return 0
 }
 
 var dupesCountOp = Operation{
@@ -213,7 +560,7 @@ var dupesCountOp = Operation{
 	},
 }
 
-func opDupesCount(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opDupesCount(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	colsI, ok := args["indexCols"]
 	if !ok {
 		return nil, nil, errors.New("indexCols argument not provided")
@@ -308,7 +655,7 @@ var findDupesOp = Operation{
 	},
 }
 
-func opFindDuplicates(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opFindDuplicates(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	var err error
 
 	var cols []string
@@ -409,7 +756,7 @@ var mergeDupesOp = Operation{
 	},
 }
 
-func opMergeDupes(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opMergeDupes(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	var err error
 
 	var cols []string
@@ -494,7 +841,7 @@ var md5FileOp = Operation{
 	},
 }
 
-func opMd5File(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+func opMd5File(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
 	var err error
 
 	var filenameCol string
@@ -544,6 +891,13 @@ func opMd5File(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, er
 		go func(r Row) {
 			ch <- 1
 
+			// once cancelled, the remaining files are not read
+			if ctx.Err() != nil {
+				<-ch
+				wg.Done()
+				return
+			}
+
 			filename := r[filenameCol].ValStr()
 
 			// if file does not exist, we return an empty string
@@ -574,6 +928,10 @@ func opMd5File(rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, er
 
 	wg.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
 	outDefs := ValueDefs{}
 	for _, h := range header {
 		outDefs[h.Name] = h
@@ -0,0 +1,375 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+var selectColumnsOp = Operation{
+	Name:   "selectColumns",
+	OpFunc: opSelectColumns,
+	ArgDef: ArgDef{
+		"cols": reflect.TypeOf([]string{}),
+	},
+}
+
+// opSelectColumns keeps only the given columns in the rows and their definitions
+func opSelectColumns(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	cols, err := argSliceString(args, "cols")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, col := range cols {
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+	}
+
+	return projectColumns(ctx, *rows, defs, cols)
+}
+
+var dropColumnsOp = Operation{
+	Name:   "dropColumns",
+	OpFunc: opDropColumns,
+	ArgDef: ArgDef{
+		"cols": reflect.TypeOf([]string{}),
+	},
+}
+
+// opDropColumns removes the given columns from the rows and their definitions
+func opDropColumns(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	dropCols, err := argSliceString(args, "cols")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	for col := range defs {
+		if !isOneOf(col, dropCols) {
+			cols = append(cols, col)
+		}
+	}
+
+	return projectColumns(ctx, *rows, defs, cols)
+}
+
+// projectColumns returns copies of the rows and of their definitions holding only the given columns
+func projectColumns(ctx context.Context, rows []Row, defs ValueDefs, cols []string) ([]Row, ValueDefs, error) {
+	outDefs := ValueDefs{}
+	for _, col := range cols {
+		outDefs[col] = defs[col]
+	}
+
+	outRows := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		outRow := Row{}
+		for _, col := range cols {
+			if val, ok := row[col]; ok {
+				outRow[col] = val
+			}
+		}
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+var addColumnOp = Operation{
+	Name:   "addColumn",
+	OpFunc: opAddColumn,
+	ArgDef: ArgDef{
+		"col":          reflect.TypeOf(""),
+		"type":         reflect.TypeOf(""),
+		"value":        reflect.TypeOf(""),
+		"expr":         reflect.TypeOf(""),
+		"parser":       reflect.TypeOf(""),
+		"parserCols":   reflect.TypeOf([]string{}),
+		"parserValues": reflect.TypeOf([]string{}),
+	},
+}
+
+// opAddColumn adds a column to the rows holding either a constant value, the result of an
+// expression over the other columns, or the output of a parser
+func opAddColumn(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var col string
+	if col, err = argString(args, "col"); err != nil {
+		return nil, nil, err
+	}
+
+	var typ string
+	if typ, err = argStringOpt(args, "type", TypStr); err != nil {
+		return nil, nil, err
+	}
+
+	var value, code, parserName string
+	if value, err = argStringOpt(args, "value", ""); err != nil {
+		return nil, nil, err
+	}
+	if code, err = argStringOpt(args, "expr", ""); err != nil {
+		return nil, nil, err
+	}
+	if parserName, err = argStringOpt(args, "parser", ""); err != nil {
+		return nil, nil, err
+	}
+
+	_, hasValue := args["value"]
+	if countSet(hasValue, code != "", parserName != "") != 1 {
+		return nil, nil, errors.New("exactly one of the value, expr or parser arguments must be provided")
+	}
+
+	def := &ColDef{Name: col, Type: typ, Dynamic: true}
+	if !colTypes[typ] {
+		return nil, nil, fmt.Errorf("unsupported type '%s' for col '%s'", typ, col)
+	}
+
+	// compute returns the value of the new column for the row
+	compute := func(row Row) (string, error) { return value, nil }
+
+	if code != "" {
+		program, err := compileExpr(code)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		compute = func(row Row) (string, error) { return evalExpr(program, row, defs) }
+	}
+
+	if parserName != "" {
+		if compute, err = parserCompute(ctx, parserName, args); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	outDefs := ValueDefs{}
+	for name, d := range defs {
+		outDefs[name] = d
+	}
+	outDefs[col] = def
+
+	outRows := make([]Row, 0, len(*rows))
+	for i, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		vStr, err := compute(row)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error computing column '%s' in row %d", col, i+1)
+		}
+
+		val, err := NewValue(def, vStr)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error computing column '%s' in row %d", col, i+1)
+		}
+
+		outRow := Row{}
+		for name, v := range row {
+			outRow[name] = v
+		}
+		outRow[col] = val
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+// parserCompute returns the function running the parser on a row. parserCols maps the parser
+// arguments to columns, as 'arg=col', or 'arg=col1,col2' for list arguments, and parserValues
+// provides constant arguments, as 'arg=value'
+func parserCompute(ctx context.Context, parserName string, args FuncArgs) (func(row Row) (string, error), error) {
+	parser, ok := parsers[parserName]
+	if !ok {
+		return nil, fmt.Errorf("parser '%s' does not exist", parserName)
+	}
+
+	parserCols, err := argSliceStringOpt(args, "parserCols", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	parserValues, err := argSliceStringOpt(args, "parserValues", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	consts := FuncArgs{}
+	for _, pv := range parserValues {
+		name, val, err := splitArgAssignment(pv, parser)
+		if err != nil {
+			return nil, err
+		}
+		consts[name] = val
+	}
+
+	colArgs := map[string][]string{}
+	for _, pc := range parserCols {
+		name, cols, err := splitArgAssignment(pc, parser)
+		if err != nil {
+			return nil, err
+		}
+		colArgs[name] = strings.Split(cols, ",")
+	}
+
+	return func(row Row) (string, error) {
+		funcArgs := FuncArgs{}
+		for name, val := range consts {
+			funcArgs[name] = val
+		}
+
+		for name, cols := range colArgs {
+			if parser.ArgDef()[name].Kind() != reflect.Slice {
+				funcArgs[name] = valStr(row, strings.TrimSpace(cols[0]))
+				continue
+			}
+
+			var vals []interface{}
+			for _, col := range cols {
+				vals = append(vals, valStr(row, strings.TrimSpace(col)))
+			}
+			funcArgs[name] = vals
+		}
+
+		return parser.Parse(withRow(ctx, row), funcArgs)
+	}, nil
+}
+
+// splitArgAssignment splits a parser argument assignment 'arg=value' and checks that
+// the parser takes the argument
+func splitArgAssignment(assignment string, parser ParserI) (string, string, error) {
+	parts := strings.SplitN(assignment, "=", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid parser argument '%s', expecting 'arg=value'", assignment)
+	}
+
+	name := strings.TrimSpace(parts[0])
+	if _, ok := parser.ArgDef()[name]; !ok {
+		return "", "", fmt.Errorf("parser '%s' does not take argument '%s'", parser.Name(), name)
+	}
+
+	return name, parts[1], nil
+}
+
+// countSet returns the number of conditions which are true
+func countSet(conds ...bool) int {
+	n := 0
+	for _, c := range conds {
+		if c {
+			n++
+		}
+	}
+
+	return n
+}
+
+var mergeColumnsOp = Operation{
+	Name:   "mergeColumns",
+	OpFunc: opMergeColumns,
+	ArgDef: ArgDef{
+		"col":       reflect.TypeOf(""),
+		"template":  reflect.TypeOf(""),
+		"skipEmpty": reflect.TypeOf(true),
+	},
+}
+
+// opMergeColumns adds a string column combining other columns with a template, eg. '{street}, {city} {postcode}'.
+// Unless skipEmpty is false, empty values are skipped along with the text following them in the template
+func opMergeColumns(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var col string
+	if col, err = argString(args, "col"); err != nil {
+		return nil, nil, err
+	}
+
+	var template string
+	if template, err = argString(args, "template"); err != nil {
+		return nil, nil, err
+	}
+
+	var skipEmpty bool
+	if skipEmpty, err = argBoolOpt(args, "skipEmpty", true); err != nil {
+		return nil, nil, err
+	}
+
+	// the template is split into its columns and the text around them, texts[i] preceding cols[i]
+	// and the last text following the last column
+	var cols, texts []string
+	start := 0
+	for _, loc := range placeholderRegexp.FindAllStringSubmatchIndex(template, -1) {
+		name := template[loc[2]:loc[3]]
+		if _, ok := defs[name]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' referenced in template not found", name)
+		}
+
+		cols = append(cols, name)
+		texts = append(texts, template[start:loc[0]])
+		start = loc[1]
+	}
+	texts = append(texts, template[start:])
+
+	def := &ColDef{Name: col, Type: TypStr, Dynamic: true}
+
+	outDefs := ValueDefs{}
+	for name, d := range defs {
+		outDefs[name] = d
+	}
+	outDefs[col] = def
+
+	outRows := make([]Row, 0, len(*rows))
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		val, err := NewValue(def, mergeTemplate(row, cols, texts, skipEmpty))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		outRow := Row{}
+		for name, v := range row {
+			outRow[name] = v
+		}
+		outRow[col] = val
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+// mergeTemplate renders the template split into its columns and texts for the row. When skipping
+// empty values, each value is followed by the text following it in the template, except for the
+// last one which is followed by the end of the template
+func mergeTemplate(row Row, cols, texts []string, skipEmpty bool) string {
+	var sb strings.Builder
+	sb.WriteString(texts[0])
+
+	var last string
+	for i, col := range cols {
+		val := valStr(row, col)
+		if skipEmpty && val == "" {
+			continue
+		}
+
+		sb.WriteString(last)
+		sb.WriteString(val)
+		last = texts[i+1]
+	}
+
+	sb.WriteString(texts[len(texts)-1])
+
+	return sb.String()
+}
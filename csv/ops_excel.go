@@ -0,0 +1,171 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// defaultSheet is the name of the worksheet of the rows written by the toExcel operation
+const defaultSheet = "Sheet1"
+
+var toExcelOp = Operation{
+	Name:   "toExcel",
+	OpFunc: opToExcel,
+	ArgDef: ArgDef{
+		"filename":   reflect.TypeOf(""),
+		"cols":       reflect.TypeOf([]string{}),
+		"sheet":      reflect.TypeOf(""),
+		"sheets":     reflect.TypeOf([]string{}),
+		"boldHeader": reflect.TypeOf(true),
+	},
+}
+
+// excelSheet is a worksheet written by the toExcel operation
+type excelSheet struct {
+	name string
+	rows []Row
+	defs ValueDefs
+	cols []string
+}
+
+// opToExcel writes the columns of the rows to a worksheet of an xlsx file, the values being typed
+// according to their columns. Kept states can be written to additional worksheets with the sheets
+// argument, as 'sheet=state', all the columns of the states being written. '-' writes to the output of the run
+func opToExcel(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceStringOpt(args, "cols", sortedCols(defs)); err != nil {
+		return nil, nil, err
+	}
+
+	var sheet string
+	if sheet, err = argStringOpt(args, "sheet", defaultSheet); err != nil {
+		return nil, nil, err
+	}
+
+	var sheetStates []string
+	if sheetStates, err = argSliceStringOpt(args, "sheets", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var boldHeader bool
+	if boldHeader, err = argBoolOpt(args, "boldHeader", true); err != nil {
+		return nil, nil, err
+	}
+
+	sheets := []excelSheet{{name: sheet, rows: *rows, defs: defs, cols: cols}}
+	for _, ss := range sheetStates {
+		parts := strings.SplitN(ss, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("invalid sheet '%s', expecting 'sheet=state'", ss)
+		}
+		name, stateName := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		state, ok := env.States[stateName]
+		if !ok {
+			return nil, nil, fmt.Errorf("state '%s' does not exist or was never kept", stateName)
+		}
+
+		sheets = append(sheets, excelSheet{name: name, rows: state.Rows, defs: state.Defs, cols: sortedCols(state.Defs)})
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	headerStyle := 0
+	if boldHeader {
+		if headerStyle, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for i, s := range sheets {
+		if i == 0 {
+			err = f.SetSheetName(defaultSheet, s.name)
+		} else {
+			_, err = f.NewSheet(s.name)
+		}
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error creating sheet '%s'", s.name)
+		}
+
+		if err := writeSheet(ctx, f, s, headerStyle); err != nil {
+			return nil, nil, errors.Wrapf(err, "error writing sheet '%s'", s.name)
+		}
+	}
+
+	return nil, nil, writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		_, err := f.WriteTo(out)
+		return err
+	})
+}
+
+// writeSheet writes the header and the rows of the sheet to its worksheet
+func writeSheet(ctx context.Context, f *excelize.File, s excelSheet, headerStyle int) error {
+	sw, err := f.NewStreamWriter(s.name)
+	if err != nil {
+		return err
+	}
+
+	header := make([]interface{}, 0, len(s.cols))
+	for _, col := range s.cols {
+		header = append(header, col)
+	}
+
+	if err := sw.SetRow("A1", header, excelize.RowOpts{StyleID: headerStyle}); err != nil {
+		return err
+	}
+
+	for i, row := range s.rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		m := row.Map(s.defs, s.cols)
+
+		cells := make([]interface{}, 0, len(s.cols))
+		for _, col := range s.cols {
+			cells = append(cells, excelValue(m[col]))
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+
+		if err := sw.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+
+	return sw.Flush()
+}
+
+// excelValue returns the value of a cell from the typed value of a row, nil pointers being empty cells
+func excelValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return v
+	}
+
+	if rv.IsNil() {
+		return nil
+	}
+
+	return rv.Elem().Interface()
+}
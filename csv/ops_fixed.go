@@ -0,0 +1,174 @@
+package csv
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alignments of the values in the fields of the toFixedWidth operation
+const (
+	AlignLeft  = "left"
+	AlignRight = "right"
+)
+
+var toFixedWidthOp = Operation{
+	Name:   "toFixedWidth",
+	OpFunc: opToFixedWidth,
+	ArgDef: ArgDef{
+		"filename":    reflect.TypeOf(""),
+		"cols":        reflect.TypeOf([]string{}),
+		"widths":      reflect.TypeOf([]string{}),
+		"align":       reflect.TypeOf([]string{}),
+		"pad":         reflect.TypeOf([]string{}),
+		"onOverflow":  reflect.TypeOf(""),
+		"crlf":        reflect.TypeOf(true),
+		"encoding":    reflect.TypeOf(""),
+		"compression": reflect.TypeOf(""),
+	},
+}
+
+// fixedField is a field of the lines written by the toFixedWidth operation
+type fixedField struct {
+	col   string
+	width int
+	align string
+	pad   string
+}
+
+// format returns the value aligned and padded to the width of the field. Longer values are
+// truncated, or are an error when not truncating
+func (f fixedField) format(val string, truncate bool) (string, error) {
+	n := utf8.RuneCountInString(val)
+	if n > f.width {
+		if !truncate {
+			return "", fmt.Errorf("value '%s' of column '%s' is longer than %d characters", val, f.col, f.width)
+		}
+
+		return string([]rune(val)[:f.width]), nil
+	}
+
+	padding := strings.Repeat(f.pad, f.width-n)
+	if f.align == AlignRight {
+		return padding + val, nil
+	}
+
+	return val + padding, nil
+}
+
+// opToFixedWidth writes the rows to a positional flat file, each column being written in a field
+// of fixed width, left aligned and padded with spaces by default. '-' writes to the output of the run
+func opToFixedWidth(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols, widths, align, pad []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+	if widths, err = argSliceString(args, "widths"); err != nil {
+		return nil, nil, err
+	}
+	if align, err = argSliceStringOpt(args, "align", nil); err != nil {
+		return nil, nil, err
+	}
+	if pad, err = argSliceStringOpt(args, "pad", nil); err != nil {
+		return nil, nil, err
+	}
+
+	if len(widths) != len(cols) || (align != nil && len(align) != len(cols)) || (pad != nil && len(pad) != len(cols)) {
+		return nil, nil, errors.New("number of items in 'widths', 'align' and 'pad' must be equal to number of items in 'cols'")
+	}
+
+	var onOverflow string
+	if onOverflow, err = argStringOpt(args, "onOverflow", "truncate"); err != nil {
+		return nil, nil, err
+	}
+
+	if onOverflow != "truncate" && onOverflow != "error" {
+		return nil, nil, errors.New("onOverflow must either be 'truncate' or 'error'")
+	}
+
+	var crlf bool
+	if crlf, err = argBoolOpt(args, "crlf", false); err != nil {
+		return nil, nil, err
+	}
+
+	var encodingName string
+	if encodingName, err = argStringOpt(args, "encoding", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var fields []fixedField
+	for i, col := range cols {
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+
+		f := fixedField{col: col, align: AlignLeft, pad: " "}
+
+		if f.width, err = strconv.Atoi(widths[i]); err != nil || f.width < 1 {
+			return nil, nil, fmt.Errorf("width of column '%s' must be a positive integer, got '%s'", col, widths[i])
+		}
+
+		if align != nil {
+			f.align = align[i]
+		}
+		if f.align != AlignLeft && f.align != AlignRight {
+			return nil, nil, fmt.Errorf("align of column '%s' must either be '%s' or '%s'", col, AlignLeft, AlignRight)
+		}
+
+		if pad != nil {
+			f.pad = pad[i]
+		}
+		if utf8.RuneCountInString(f.pad) != 1 {
+			return nil, nil, fmt.Errorf("pad of column '%s' must be a single character, got '%s'", col, f.pad)
+		}
+
+		fields = append(fields, f)
+	}
+
+	eol := "\n"
+	if crlf {
+		eol = "\r\n"
+	}
+
+	return nil, nil, writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		enc, err := encodeWriter(out, encodingName)
+		if err != nil {
+			return err
+		}
+
+		w := bufio.NewWriter(enc)
+		for i, row := range *rows {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			for _, f := range fields {
+				val, err := f.format(valStr(row, f.col), onOverflow == "truncate")
+				if err != nil {
+					return errors.Wrapf(err, "error writing row %d", i+1)
+				}
+				w.WriteString(val)
+			}
+			w.WriteString(eol)
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		return enc.Close()
+	})
+}
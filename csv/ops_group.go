@@ -0,0 +1,216 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Aggregate functions of the groupBy operation
+const (
+	AggCount  = "count"
+	AggSum    = "sum"
+	AggAvg    = "avg"
+	AggMin    = "min"
+	AggMax    = "max"
+	AggFirst  = "first"
+	AggLast   = "last"
+	AggConcat = "concat"
+)
+
+var groupByOp = Operation{
+	Name:   "groupBy",
+	OpFunc: opGroupBy,
+	ArgDef: ArgDef{
+		"indexCols":  reflect.TypeOf([]string{}),
+		"aggregates": reflect.TypeOf([]string{}),
+		"sep":        reflect.TypeOf(""),
+	},
+}
+
+// aggregateRegexp matches an aggregate definition, eg. 'total=sum(amount)' or 'n=count'
+var aggregateRegexp = regexp.MustCompile(`^\s*(\w+)\s*=\s*(\w+)\s*(?:\(\s*([^)]*?)\s*\))?\s*$`)
+
+// aggregate is an output column of the groupBy operation computed from the rows of each group
+type aggregate struct {
+	col  string // name of the output column
+	fn   string // the aggregate function
+	from string // the column the values are aggregated from, empty for count
+}
+
+// parseAggregate parses an aggregate definition such as 'total=sum(amount)'
+func parseAggregate(def string, defs ValueDefs) (*aggregate, error) {
+	m := aggregateRegexp.FindStringSubmatch(def)
+	if m == nil {
+		return nil, fmt.Errorf("invalid aggregate '%s', expecting 'col=function(col)'", def)
+	}
+
+	agg := &aggregate{col: m[1], fn: m[2], from: m[3]}
+
+	switch agg.fn {
+	case AggCount:
+		return agg, nil
+	case AggSum, AggAvg, AggMin, AggMax, AggFirst, AggLast, AggConcat:
+	default:
+		return nil, fmt.Errorf("unsupported aggregate function '%s' in '%s'", agg.fn, def)
+	}
+
+	fromDef, ok := defs[agg.from]
+	if !ok {
+		return nil, fmt.Errorf("column '%s' not found in '%s'", agg.from, def)
+	}
+
+	if (agg.fn == AggSum || agg.fn == AggAvg) && fromDef.Type != TypInt && fromDef.Type != TypFloat {
+		return nil, fmt.Errorf("column '%s' must be an int or a float to compute its %s", agg.from, agg.fn)
+	}
+
+	return agg, nil
+}
+
+// def returns the definition of the output column
+func (agg *aggregate) def(defs ValueDefs) *ColDef {
+	switch agg.fn {
+	case AggCount:
+		return &ColDef{Name: agg.col, Type: TypInt, Dynamic: true}
+	case AggSum, AggAvg:
+		return &ColDef{Name: agg.col, Type: TypFloat, Dynamic: true}
+	case AggConcat:
+		return &ColDef{Name: agg.col, Type: TypStr, Dynamic: true}
+	}
+
+	from := defs[agg.from]
+	return &ColDef{Name: agg.col, Type: from.Type, Layout: from.Layout, Dynamic: true}
+}
+
+// compute returns the aggregated value of the rows of the group
+func (agg *aggregate) compute(grp []Row, defs ValueDefs, sep string) string {
+	switch agg.fn {
+	case AggCount:
+		return strconv.Itoa(len(grp))
+	case AggFirst:
+		return valStr(grp[0], agg.from)
+	case AggLast:
+		return valStr(grp[len(grp)-1], agg.from)
+	case AggConcat:
+		var vals []string
+		for _, row := range grp {
+			vals = append(vals, valStr(row, agg.from))
+		}
+		return strings.Join(vals, sep)
+	case AggSum, AggAvg:
+		var sum float64
+		for _, row := range grp {
+			if v := row[agg.from].ValFloat(); v != nil {
+				sum += *v
+			}
+		}
+
+		if agg.fn == AggAvg {
+			sum /= float64(len(grp))
+		}
+		return strconv.FormatFloat(sum, 'f', -1, 64)
+	}
+
+	// min and max, comparing values according to the type of the column
+	order := []string{"asc"}
+	if agg.fn == AggMax {
+		order = []string{"desc"}
+	}
+
+	best := grp[0]
+	for _, row := range grp[1:] {
+		if rowLess(row, best, defs, []string{agg.from}, order) {
+			best = row
+		}
+	}
+
+	return valStr(best, agg.from)
+}
+
+// opGroupBy groups the rows by the values of the index columns, and computes the aggregate
+// columns from the rows of each group. Groups are returned in the order they first appear
+func opGroupBy(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var cols []string
+	if cols, err = argSliceString(args, "indexCols"); err != nil {
+		return nil, nil, err
+	}
+
+	var aggDefs []string
+	if aggDefs, err = argSliceString(args, "aggregates"); err != nil {
+		return nil, nil, err
+	}
+
+	var sep string
+	if sep, err = argStringOpt(args, "sep", ","); err != nil {
+		return nil, nil, err
+	}
+
+	header := Header{}
+	for i, col := range cols {
+		def, ok := defs[col]
+		if !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+		header[i] = def
+	}
+
+	var aggs []*aggregate
+	for _, aggDef := range aggDefs {
+		agg, err := parseAggregate(aggDef, defs)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		aggs = append(aggs, agg)
+		header[len(header)] = agg.def(defs)
+	}
+
+	var keys []string
+	m := map[string][]Row{}
+	for _, row := range *rows {
+		key := rowKey(row, cols)
+
+		if _, ok := m[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		m[key] = append(m[key], row)
+	}
+
+	var outRows []Row
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		grp := m[key]
+
+		var rec []string
+		for _, col := range cols {
+			rec = append(rec, valStr(grp[0], col))
+		}
+
+		for _, agg := range aggs {
+			rec = append(rec, agg.compute(grp, defs, sep))
+		}
+
+		grpRow, err := NewRow(header, rec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		outRows = append(outRows, grpRow)
+	}
+
+	outDefs := ValueDefs{}
+	for _, h := range header {
+		outDefs[h.Name] = h
+	}
+
+	return outRows, outDefs, nil
+}
@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"reflect"
+)
+
+var toHtmlOp = Operation{
+	Name:   "toHtml",
+	OpFunc: opToHtml,
+	ArgDef: ArgDef{
+		"filename": reflect.TypeOf(""),
+		"cols":     reflect.TypeOf([]string{}),
+		"title":    reflect.TypeOf(""),
+	},
+}
+
+// htmlTemplate is the standalone page written by the toHtml operation. Clicking on a column
+// header sorts the table by the column, numbers being compared as such
+var htmlTemplate = template.Must(template.New("toHtml").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+tr:nth-child(even) td { background: #fafafa; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{len .Rows}} rows</p>
+<table>
+<thead><tr>{{range .Cols}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<script>
+document.querySelectorAll("th").forEach(function (th, col) {
+  var asc = true;
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var rows = Array.from(tbody.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[col].textContent, y = b.cells[col].textContent;
+      var cmp = x !== "" && y !== "" && !isNaN(x) && !isNaN(y) ? x - y : x.localeCompare(y);
+      return asc ? cmp : -cmp;
+    });
+    asc = !asc;
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// opToHtml writes the columns of the rows to a standalone HTML page holding a sortable table,
+// eg. to share a report. '-' writes to the output of the run
+func opToHtml(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var title string
+	if title, err = argStringOpt(args, "title", "csv-chef"); err != nil {
+		return nil, nil, err
+	}
+
+	var recs [][]string
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		rec := make([]string, 0, len(cols))
+		for _, col := range cols {
+			rec = append(rec, valStr(row, col))
+		}
+
+		recs = append(recs, rec)
+	}
+
+	data := struct {
+		Title string
+		Cols  []string
+		Rows  [][]string
+	}{title, cols, recs}
+
+	return nil, nil, writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		return htmlTemplate.Execute(out, data)
+	})
+}
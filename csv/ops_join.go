@@ -0,0 +1,689 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Join types
+const (
+	JoinInner = "inner" // only the rows matching on both sides
+	JoinLeft  = "left"  // all the current rows, and the matching rows of the other side
+	JoinRight = "right" // all the rows of the other side, and the matching current rows
+	JoinFull  = "full"  // all the rows of both sides
+)
+
+var joinOp = Operation{
+	Name:   "join",
+	OpFunc: opJoin,
+	ArgDef: ArgDef{
+		"state":   reflect.TypeOf(""),
+		"file":    reflect.TypeOf(""),
+		"on":      reflect.TypeOf([]string{}),
+		"otherOn": reflect.TypeOf([]string{}),
+		"type":    reflect.TypeOf(""),
+		"prefix":  reflect.TypeOf(""),
+	},
+}
+
+// opJoin joins the rows with the rows of a kept state or of a second CSV file on the key
+// columns. The columns of the other side which collide with the current ones are prefixed
+func opJoin(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var on []string
+	if on, err = argSliceString(args, "on"); err != nil {
+		return nil, nil, err
+	}
+
+	var otherOn []string
+	if otherOn, err = argSliceStringOpt(args, "otherOn", on); err != nil {
+		return nil, nil, err
+	}
+
+	if len(on) != len(otherOn) {
+		return nil, nil, errors.New("number of items in 'otherOn' must be equal to number of items in 'on'")
+	}
+
+	var joinType string
+	if joinType, err = argStringOpt(args, "type", JoinInner); err != nil {
+		return nil, nil, err
+	}
+
+	if joinType != JoinInner && joinType != JoinLeft && joinType != JoinRight && joinType != JoinFull {
+		return nil, nil, fmt.Errorf("type must either be '%s', '%s', '%s' or '%s'", JoinInner, JoinLeft, JoinRight, JoinFull)
+	}
+
+	var prefix string
+	if prefix, err = argStringOpt(args, "prefix", "right_"); err != nil {
+		return nil, nil, err
+	}
+
+	otherRows, otherDefs, err := sourceRows(ctx, env, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the key columns of the other side are merged into the current ones,
+	// otherCols maps the output columns to their column on the other side
+	otherCols := map[string]string{}
+	for i, col := range on {
+		otherCols[col] = otherOn[i]
+	}
+
+	// the output holds all current columns followed by the other columns, prefixed on collision
+	header := Header{}
+	for _, col := range sortedCols(defs) {
+		header[len(header)] = defs[col]
+	}
+
+	for _, col := range sortedCols(otherDefs) {
+		if isOneOf(col, otherOn) {
+			continue
+		}
+
+		name := col
+		if _, ok := defs[col]; ok {
+			name = prefix + col
+		}
+		otherCols[name] = col
+
+		header[len(header)] = &ColDef{Name: name, Type: otherDefs[col].Type, Layout: otherDefs[col].Layout, Dynamic: true}
+	}
+
+	index := map[string][]Row{}
+	for _, row := range otherRows {
+		key := rowKey(row, otherOn)
+		index[key] = append(index[key], row)
+	}
+
+	matched := map[string]bool{}
+	var outRows []Row
+
+	// the values of both sides are kept as they are, the columns of the missing side being left empty.
+	// The key columns of the rows only found on the other side are taken from its otherOn columns
+	addRow := func(row Row, other Row) {
+		outRow := Row{}
+		for i := 0; i < len(header); i++ {
+			name := header[i].Name
+
+			if val, ok := row[name]; ok && val != nil {
+				outRow[name] = val
+				continue
+			}
+
+			if otherCol, ok := otherCols[name]; ok {
+				if val, ok := other[otherCol]; ok && val != nil {
+					outRow[name] = val
+					continue
+				}
+			}
+
+			outRow[name] = emptyValue(header[i])
+		}
+
+		outRows = append(outRows, outRow)
+	}
+
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		key := rowKey(row, on)
+		others, ok := index[key]
+
+		if !ok {
+			if joinType == JoinLeft || joinType == JoinFull {
+				addRow(row, nil)
+			}
+			continue
+		}
+
+		matched[key] = true
+		for _, other := range others {
+			addRow(row, other)
+		}
+	}
+
+	if joinType == JoinRight || joinType == JoinFull {
+		for _, other := range otherRows {
+			if matched[rowKey(other, otherOn)] {
+				continue
+			}
+
+			addRow(nil, other)
+		}
+	}
+
+	outDefs := ValueDefs{}
+	for _, h := range header {
+		outDefs[h.Name] = h
+	}
+
+	return outRows, outDefs, nil
+}
+
+// Behaviours of the lookup operation when a key is not found in the reference rows
+const (
+	LookupMissEmpty   = "empty"   // the looked up columns are left empty
+	LookupMissDefault = "default" // the looked up columns are set to the default value
+	LookupMissError   = "error"   // the operation fails
+)
+
+var lookupOp = Operation{
+	Name:   "lookup",
+	OpFunc: opLookup,
+	ArgDef: ArgDef{
+		"state":   reflect.TypeOf(""),
+		"file":    reflect.TypeOf(""),
+		"on":      reflect.TypeOf([]string{}),
+		"otherOn": reflect.TypeOf([]string{}),
+		"cols":    reflect.TypeOf([]string{}),
+		"prefix":  reflect.TypeOf(""),
+		"onMiss":  reflect.TypeOf(""),
+		"default": reflect.TypeOf(""),
+	},
+}
+
+// opLookup enriches every row with the columns of the first reference row matching its key, the
+// reference rows being read from a kept state or a CSV file. Unlike join, the number of rows
+// never changes
+func opLookup(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var on []string
+	if on, err = argSliceString(args, "on"); err != nil {
+		return nil, nil, err
+	}
+
+	var otherOn []string
+	if otherOn, err = argSliceStringOpt(args, "otherOn", on); err != nil {
+		return nil, nil, err
+	}
+
+	if len(on) != len(otherOn) {
+		return nil, nil, errors.New("number of items in 'otherOn' must be equal to number of items in 'on'")
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var prefix string
+	if prefix, err = argStringOpt(args, "prefix", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var onMiss string
+	if onMiss, err = argStringOpt(args, "onMiss", LookupMissEmpty); err != nil {
+		return nil, nil, err
+	}
+
+	if onMiss != LookupMissEmpty && onMiss != LookupMissDefault && onMiss != LookupMissError {
+		return nil, nil, fmt.Errorf("onMiss must either be '%s', '%s' or '%s'", LookupMissEmpty, LookupMissDefault, LookupMissError)
+	}
+
+	var defaultVal string
+	if defaultVal, err = argStringOpt(args, "default", ""); err != nil {
+		return nil, nil, err
+	}
+
+	refRows, refDefs, err := sourceRows(ctx, env, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outDefs := ValueDefs{}
+	for name, d := range defs {
+		outDefs[name] = d
+	}
+
+	// lookupDefs are the definitions of the looked up columns, in the order of cols
+	var lookupDefs []*ColDef
+	for _, col := range cols {
+		refDef, ok := refDefs[col]
+		if !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found in the reference rows", col)
+		}
+
+		name := prefix + col
+		if _, ok := defs[name]; ok {
+			return nil, nil, fmt.Errorf("column '%s' already exists, a prefix must be provided", name)
+		}
+
+		def := &ColDef{Name: name, Type: refDef.Type, Layout: refDef.Layout, Dynamic: true}
+		lookupDefs = append(lookupDefs, def)
+		outDefs[name] = def
+	}
+
+	// the first reference row of each key is used
+	index := map[string]Row{}
+	for _, ref := range refRows {
+		key := rowKey(ref, otherOn)
+		if _, ok := index[key]; !ok {
+			index[key] = ref
+		}
+	}
+
+	outRows := make([]Row, 0, len(*rows))
+	for i, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		key := rowKey(row, on)
+		ref, ok := index[key]
+		if !ok && onMiss == LookupMissError {
+			return nil, nil, fmt.Errorf("key '%s' of row %d not found in the reference rows", strings.ReplaceAll(key, "\x00", ","), i+1)
+		}
+
+		outRow := Row{}
+		for name, v := range row {
+			outRow[name] = v
+		}
+
+		for j, def := range lookupDefs {
+			vStr := ""
+			if ok {
+				vStr = valStr(ref, cols[j])
+			} else if onMiss == LookupMissDefault {
+				vStr = defaultVal
+			}
+
+			val, err := NewValue(def, vStr)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "error looking up column '%s' in row %d", def.Name, i+1)
+			}
+			outRow[def.Name] = val
+		}
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+var antiJoinOp = Operation{
+	Name:   "antiJoin",
+	OpFunc: opAntiJoin,
+	ArgDef: ArgDef{
+		"state":   reflect.TypeOf(""),
+		"file":    reflect.TypeOf(""),
+		"on":      reflect.TypeOf([]string{}),
+		"otherOn": reflect.TypeOf([]string{}),
+	},
+}
+
+// opAntiJoin keeps only the rows whose key is not found in the rows of a kept state or of a
+// CSV file, eg. a suppression list. The rows are kept unchanged
+func opAntiJoin(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var on []string
+	if on, err = argSliceString(args, "on"); err != nil {
+		return nil, nil, err
+	}
+
+	var otherOn []string
+	if otherOn, err = argSliceStringOpt(args, "otherOn", on); err != nil {
+		return nil, nil, err
+	}
+
+	if len(on) != len(otherOn) {
+		return nil, nil, errors.New("number of items in 'otherOn' must be equal to number of items in 'on'")
+	}
+
+	otherRows, _, err := sourceRows(ctx, env, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	excluded := map[string]bool{}
+	for _, row := range otherRows {
+		excluded[rowKey(row, otherOn)] = true
+	}
+
+	var outRows []Row
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		if !excluded[rowKey(row, on)] {
+			outRows = append(outRows, row)
+		}
+	}
+
+	return outRows, defs, nil
+}
+
+var unionOp = Operation{
+	Name:   "union",
+	OpFunc: opUnion,
+	ArgDef: ArgDef{
+		"state": reflect.TypeOf(""),
+		"file":  reflect.TypeOf(""),
+	},
+}
+
+// opUnion appends the rows of a kept state or of a second CSV file to the rows. Columns are
+// matched by name, the columns missing on either side taking their default value
+func opUnion(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	otherRows, otherDefs, err := sourceRows(ctx, env, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the current definitions prevail for the columns found on both sides
+	outDefs := ValueDefs{}
+	for col, def := range otherDefs {
+		outDefs[col] = def
+	}
+	for col, def := range defs {
+		outDefs[col] = def
+	}
+
+	outRows := make([]Row, 0, len(*rows)+len(otherRows))
+	for _, set := range []struct {
+		rows []Row
+		defs ValueDefs
+	}{{*rows, defs}, {otherRows, otherDefs}} {
+		for _, row := range set.rows {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+
+			outRow := Row{}
+			for col, def := range outDefs {
+				// values are kept as is if they were parsed with the same definition
+				if val, ok := row[col]; ok && val != nil && set.defs[col] == def {
+					outRow[col] = val
+					continue
+				}
+
+				if outRow[col], err = NewValue(def, valStr(row, col)); err != nil {
+					return nil, nil, errors.Wrapf(err, "error converting column '%s'", col)
+				}
+			}
+
+			outRows = append(outRows, outRow)
+		}
+	}
+
+	return outRows, outDefs, nil
+}
+
+// Values of the change column of the diff operation
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+var diffOp = Operation{
+	Name:   "diff",
+	OpFunc: opDiff,
+	ArgDef: ArgDef{
+		"state":       reflect.TypeOf(""),
+		"file":        reflect.TypeOf(""),
+		"on":          reflect.TypeOf([]string{}),
+		"compareCols": reflect.TypeOf([]string{}),
+		"changeCol":   reflect.TypeOf(""),
+	},
+}
+
+// opDiff compares the rows to the rows of a kept state or of a second CSV file, taken as the
+// previous version, on the key columns. It returns the rows which were added, removed or changed
+// along with a column holding the change. Changed rows hold the current values
+func opDiff(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var on []string
+	if on, err = argSliceString(args, "on"); err != nil {
+		return nil, nil, err
+	}
+
+	var changeCol string
+	if changeCol, err = argStringOpt(args, "changeCol", "__change"); err != nil {
+		return nil, nil, err
+	}
+
+	otherRows, otherDefs, err := sourceRows(ctx, env, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// by default, all the columns found on both sides which are not part of the key are compared
+	var defaultCompareCols []string
+	for _, col := range sortedCols(defs) {
+		if _, ok := otherDefs[col]; ok && !isOneOf(col, on) {
+			defaultCompareCols = append(defaultCompareCols, col)
+		}
+	}
+
+	var compareCols []string
+	if compareCols, err = argSliceStringOpt(args, "compareCols", defaultCompareCols); err != nil {
+		return nil, nil, err
+	}
+
+	outDefs := ValueDefs{}
+	for col, def := range otherDefs {
+		outDefs[col] = def
+	}
+	for col, def := range defs {
+		outDefs[col] = def
+	}
+
+	changeDef := &ColDef{Name: changeCol, Type: TypStr, Dynamic: true}
+	outDefs[changeCol] = changeDef
+
+	// diffRow returns a copy of the row holding all output columns and the change
+	diffRow := func(row Row, rowDefs ValueDefs, change string) (Row, error) {
+		outRow := Row{}
+		for col, def := range outDefs {
+			if col == changeCol {
+				continue
+			}
+
+			if val, ok := row[col]; ok && val != nil && rowDefs[col] == def {
+				outRow[col] = val
+				continue
+			}
+
+			val, err := NewValue(def, valStr(row, col))
+			if err != nil {
+				return nil, errors.Wrapf(err, "error converting column '%s'", col)
+			}
+			outRow[col] = val
+		}
+
+		val, err := NewValue(changeDef, change)
+		if err != nil {
+			return nil, err
+		}
+		outRow[changeCol] = val
+
+		return outRow, nil
+	}
+
+	previous := map[string]Row{}
+	for _, row := range otherRows {
+		previous[rowKey(row, on)] = row
+	}
+
+	current := map[string]bool{}
+	var outRows []Row
+
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		key := rowKey(row, on)
+		current[key] = true
+
+		change := ChangeAdded
+		if prev, ok := previous[key]; ok {
+			if rowKey(row, compareCols) == rowKey(prev, compareCols) {
+				continue
+			}
+			change = ChangeChanged
+		}
+
+		outRow, err := diffRow(row, defs, change)
+		if err != nil {
+			return nil, nil, err
+		}
+		outRows = append(outRows, outRow)
+	}
+
+	for _, row := range otherRows {
+		if current[rowKey(row, on)] {
+			continue
+		}
+
+		outRow, err := diffRow(row, otherDefs, ChangeRemoved)
+		if err != nil {
+			return nil, nil, err
+		}
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+// isOneOf returns whether the column is one of the columns
+func isOneOf(col string, cols []string) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valStr returns the string value of the column in the row, or an empty string if it has no value
+func valStr(row Row, col string) string {
+	val, ok := row[col]
+	if !ok || val == nil {
+		return ""
+	}
+
+	return val.ValStr()
+}
+
+// rowKey returns the index key of the row made of the values of the columns
+func rowKey(row Row, cols []string) string {
+	var vals []string
+	for _, col := range cols {
+		vals = append(vals, valStr(row, col))
+	}
+
+	return strings.Join(vals, "\x00")
+}
+
+// sortedCols returns the names of the columns sorted alphabetically
+func sortedCols(defs ValueDefs) []string {
+	var cols []string
+	for col := range defs {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	return cols
+}
+
+// sourceRows returns the rows operations combine the current rows with, either from the
+// state kept under the name given in the 'state' argument, or from the CSV file given in
+// the 'file' argument. All the columns of the file are read as strings
+func sourceRows(ctx context.Context, env *OpEnv, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var stateName string
+	if stateName, err = argStringOpt(args, "state", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var fileName string
+	if fileName, err = argStringOpt(args, "file", ""); err != nil {
+		return nil, nil, err
+	}
+
+	if (stateName == "") == (fileName == "") {
+		return nil, nil, errors.New("either the state or the file argument must be provided")
+	}
+
+	if stateName != "" {
+		state, ok := env.States[stateName]
+		if !ok {
+			return nil, nil, fmt.Errorf("state '%s' does not exist or was never kept", stateName)
+		}
+
+		return state.Rows, state.Defs, nil
+	}
+
+	return readRefFile(ctx, fileName)
+}
+
+// readRefFile reads all the rows of a reference CSV file, all its columns being read as strings
+func readRefFile(ctx context.Context, fileName string) ([]Row, ValueDefs, error) {
+	f, err := openFile(ctx, fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	csvR, decompressor, err := newCsvReader(f, &InputConf{}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer decompressor.Close()
+
+	headerRec, err := csvR.Read()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error reading the header of '%s'", fileName)
+	}
+
+	defs := ValueDefs{}
+	for _, h := range headerRec {
+		h = strings.TrimSpace(h)
+		defs[h] = &ColDef{Name: h, Type: TypStr}
+	}
+
+	header, err := NewHeader(defs, headerRec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []Row
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		rec, err := csvR.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error reading '%s'", fileName)
+		}
+
+		row, err := NewRow(header, rec)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, defs, nil
+}
@@ -0,0 +1,103 @@
+package csv
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Formats of the toJson operation
+const (
+	JsonArray = "array" // a single JSON array holding all the rows
+	JsonLines = "lines" // one JSON object per line, aka. JSON Lines or NDJSON
+)
+
+var toJsonOp = Operation{
+	Name:   "toJson",
+	OpFunc: opToJson,
+	ArgDef: ArgDef{
+		"filename":    reflect.TypeOf(""),
+		"cols":        reflect.TypeOf([]string{}),
+		"format":      reflect.TypeOf(""),
+		"compression": reflect.TypeOf(""),
+	},
+}
+
+// opToJson writes the rows to a file as JSON objects, the values being typed according to their
+// columns and the fields kept in the order of cols. The format is inferred from the filename extension if not provided: JSON Lines for
+// .jsonl and .ndjson files, an array otherwise. '-' writes to the output of the run
+func opToJson(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var format string
+	if format, err = argStringOpt(args, "format", jsonFormatFromFilename(fileName)); err != nil {
+		return nil, nil, err
+	}
+
+	if format != JsonArray && format != JsonLines {
+		return nil, nil, fmt.Errorf("format must either be '%s' or '%s'", JsonArray, JsonLines)
+	}
+
+	return nil, nil, writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		w := bufio.NewWriter(out)
+
+		if format == JsonArray {
+			w.WriteString("[")
+		}
+
+		for i, row := range *rows {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			b, err := json.Marshal(row.Object(defs, cols))
+			if err != nil {
+				return err
+			}
+
+			if format == JsonArray && i > 0 {
+				w.WriteString(",")
+			}
+			if format == JsonArray {
+				w.WriteString("\n  ")
+			}
+
+			w.Write(b)
+
+			if format == JsonLines {
+				w.WriteString("\n")
+			}
+		}
+
+		if format == JsonArray {
+			w.WriteString("\n]\n")
+		}
+
+		return w.Flush()
+	})
+}
+
+// jsonFormatFromFilename returns the JSON format matching the extension of the file, ignoring
+// the compression extensions
+func jsonFormatFromFilename(fileName string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(fileName), ".gz"), ".zst")
+	if strings.HasSuffix(name, ".jsonl") || strings.HasSuffix(name, ".ndjson") {
+		return JsonLines
+	}
+
+	return JsonArray
+}
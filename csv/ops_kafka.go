@@ -0,0 +1,262 @@
+package csv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/linkedin/goavro/v2"
+	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Formats of the messages of the toKafka operation
+const (
+	KafkaJson = "json" // JSON objects, as written by toJson
+	KafkaAvro = "avro" // Avro binary encoded records
+)
+
+var toKafkaOp = Operation{
+	Name:   "toKafka",
+	OpFunc: opToKafka,
+	ArgDef: ArgDef{
+		"brokers":   reflect.TypeOf([]string{}),
+		"topic":     reflect.TypeOf(""),
+		"cols":      reflect.TypeOf([]string{}),
+		"keyCols":   reflect.TypeOf([]string{}),
+		"keySep":    reflect.TypeOf(""),
+		"batchSize": reflect.TypeOf(1),
+		"onError":   reflect.TypeOf(""),
+		"format":    reflect.TypeOf(""),
+		"schema":    reflect.TypeOf(""),
+		"schemaId":  reflect.TypeOf(1),
+	},
+}
+
+// avroNameRegexp matches the valid names of Avro records and fields
+var avroNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// avroInvalidCharsRegexp matches the characters which are not allowed in Avro names
+var avroInvalidCharsRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// avroSchema returns the schema of the Avro record named after the topic holding the columns.
+// The fields are nullable and typed according to their column, dates and timestamps being
+// strings formatted like in the JSON messages
+func avroSchema(topic string, defs ValueDefs, cols []string) (string, error) {
+	name := avroInvalidCharsRegexp.ReplaceAllString(topic, "_")
+	if !avroNameRegexp.MatchString(name) {
+		name = "_" + name
+	}
+
+	var fields []map[string]interface{}
+	for _, col := range cols {
+		if !avroNameRegexp.MatchString(col) {
+			return "", fmt.Errorf("column '%s' is not a valid avro field name, a schema must be provided", col)
+		}
+
+		typ := "string"
+		switch defs[col].Type {
+		case TypInt:
+			typ = "long"
+		case TypFloat:
+			typ = "double"
+		case TypBool:
+			typ = "boolean"
+		}
+
+		fields = append(fields, map[string]interface{}{"name": col, "type": []string{"null", typ}, "default": nil})
+	}
+
+	schema, err := json.Marshal(map[string]interface{}{"type": "record", "name": name, "fields": fields})
+	return string(schema), err
+}
+
+// avroEncoder returns the function encoding the JSON object of a row as an Avro record of the
+// schema, generated from the columns if schemaFile is empty. If schemaId is set, the records are
+// prefixed by the header of the wire format of the Confluent schema registry
+func avroEncoder(topic string, defs ValueDefs, cols []string, schemaFile string, schemaId int) (func(value []byte) ([]byte, error), error) {
+	var schema string
+	if schemaFile == "" {
+		var err error
+		if schema, err = avroSchema(topic, defs, cols); err != nil {
+			return nil, err
+		}
+	} else {
+		b, err := ioutil.ReadFile(schemaFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error reading avro schema '%s'", schemaFile)
+		}
+		schema = string(b)
+	}
+
+	// the values are given as regular JSON rather than Avro JSON, unions being bare values
+	codec, err := goavro.NewCodecForStandardJSONFull(schema)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid avro schema")
+	}
+
+	var header []byte
+	if schemaId > 0 {
+		header = make([]byte, 5)
+		binary.BigEndian.PutUint32(header[1:], uint32(schemaId))
+	}
+
+	return func(value []byte) ([]byte, error) {
+		native, _, err := codec.NativeFromTextual(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return codec.BinaryFromNative(append([]byte{}, header...), native)
+	}, nil
+}
+
+// opToKafka publishes each row as a JSON message, or an Avro record, to a Kafka topic. The
+// message key is built from the values of keyCols joined by keySep
+func opToKafka(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var brokers []string
+	if brokers, err = argSliceString(args, "brokers"); err != nil {
+		return nil, nil, err
+	}
+
+	var topic string
+	if topic, err = argString(args, "topic"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var keyCols []string
+	if keyCols, err = argSliceStringOpt(args, "keyCols", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var keySep string
+	if keySep, err = argStringOpt(args, "keySep", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var batchSize int
+	if batchSize, err = argIntOpt(args, "batchSize", 100); err != nil {
+		return nil, nil, err
+	}
+	if batchSize < 1 {
+		return nil, nil, errors.New("batchSize must be greater than 0")
+	}
+
+	var onError string
+	if onError, err = argStringOpt(args, "onError", OnErrorAbort); err != nil {
+		return nil, nil, err
+	}
+	if onError != OnErrorAbort && onError != OnErrorSkip {
+		return nil, nil, fmt.Errorf("onError must either be '%s' or '%s'", OnErrorAbort, OnErrorSkip)
+	}
+
+	for _, col := range append(append([]string{}, cols...), keyCols...) {
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+	}
+
+	var format string
+	if format, err = argStringOpt(args, "format", KafkaJson); err != nil {
+		return nil, nil, err
+	}
+
+	var schemaFile string
+	if schemaFile, err = argStringOpt(args, "schema", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var schemaId int
+	if schemaId, err = argIntOpt(args, "schemaId", 0); err != nil {
+		return nil, nil, err
+	}
+
+	var encodeAvro func(value []byte) ([]byte, error)
+	switch format {
+	case KafkaJson:
+		if schemaFile != "" || schemaId != 0 {
+			return nil, nil, fmt.Errorf("schema and schemaId are only supported by the '%s' format", KafkaAvro)
+		}
+	case KafkaAvro:
+		if encodeAvro, err = avroEncoder(topic, defs, cols, schemaFile, schemaId); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("format must either be '%s' or '%s'", KafkaJson, KafkaAvro)
+	}
+
+	w := &kafka.Writer{
+		Addr:      kafka.TCP(brokers...),
+		Topic:     topic,
+		Balancer:  &kafka.Hash{},
+		BatchSize: batchSize,
+	}
+	defer w.Close()
+
+	var batch []kafka.Message
+	flush := func(from int) error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := w.WriteMessages(ctx, batch...)
+		batch = batch[:0]
+		if err == nil {
+			return nil
+		}
+
+		if onError == OnErrorSkip {
+			logrus.Warnf("toKafka: failed delivering messages from row %d to topic '%s': %s", from, topic, err)
+			return nil
+		}
+
+		return errors.Wrapf(err, "error delivering messages from row %d to topic '%s'", from, topic)
+	}
+
+	batchStart := 0
+	for i, r := range *rows {
+		value, err := json.Marshal(r.Object(defs, cols))
+		if err == nil && encodeAvro != nil {
+			value, err = encodeAvro(value)
+		}
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error encoding row %d", i)
+		}
+
+		var key []string
+		for _, col := range keyCols {
+			key = append(key, valStr(r, col))
+		}
+
+		msg := kafka.Message{Value: value}
+		if len(key) > 0 {
+			msg.Key = []byte(strings.Join(key, keySep))
+		}
+		batch = append(batch, msg)
+
+		if len(batch) == batchSize {
+			if err := flush(batchStart); err != nil {
+				return nil, nil, err
+			}
+			batchStart = i + 1
+		}
+	}
+
+	if err := flush(batchStart); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, nil, nil
+}
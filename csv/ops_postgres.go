@@ -0,0 +1,132 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+)
+
+var toPostgresOp = Operation{
+	Name:   "toPostgres",
+	OpFunc: opToPostgres,
+	ArgDef: ArgDef{
+		"connection": reflect.TypeOf(""),
+		"table":      reflect.TypeOf(""),
+		"cols":       reflect.TypeOf([]string{}),
+		"colMap":     reflect.TypeOf([]string{}),
+		"truncate":   reflect.TypeOf(true),
+	},
+}
+
+// opToPostgres streams the rows into an existing PostgreSQL table with the binary COPY protocol,
+// in a single transaction. Columns are copied to the table columns of the same name, unless mapped
+// as 'col=tableCol'. The connection string defaults to the standard PG* environment variables
+func opToPostgres(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var connection string
+	if connection, err = argStringOpt(args, "connection", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var table string
+	if table, err = argString(args, "table"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceStringOpt(args, "cols", sortedCols(defs)); err != nil {
+		return nil, nil, err
+	}
+
+	var colMap []string
+	if colMap, err = argSliceStringOpt(args, "colMap", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var truncate bool
+	if truncate, err = argBoolOpt(args, "truncate", false); err != nil {
+		return nil, nil, err
+	}
+
+	tableCols := map[string]string{}
+	for _, m := range colMap {
+		parts := strings.SplitN(m, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("invalid column mapping '%s', expecting 'col=tableCol'", m)
+		}
+		tableCols[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	var copyCols []string
+	for _, col := range cols {
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+
+		tableCol, ok := tableCols[col]
+		if !ok {
+			tableCol = col
+		}
+		copyCols = append(copyCols, tableCol)
+	}
+
+	conn, err := pgx.Connect(ctx, connection)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error connecting to PostgreSQL")
+	}
+	defer conn.Close(context.Background())
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(context.Background())
+
+	// the table name may be qualified by its schema, eg. 'public.files'
+	tableName := pgx.Identifier(strings.Split(table, "."))
+
+	if truncate {
+		if _, err := tx.Exec(ctx, "TRUNCATE TABLE "+tableName.Sanitize()); err != nil {
+			return nil, nil, errors.Wrapf(err, "error truncating table '%s'", table)
+		}
+	}
+
+	i := 0
+	source := pgx.CopyFromFunc(func() ([]interface{}, error) {
+		if i >= len(*rows) {
+			return nil, nil
+		}
+
+		row := (*rows)[i]
+		i++
+
+		vals := make([]interface{}, 0, len(cols))
+		for _, col := range cols {
+			vals = append(vals, pgValue(row[col], defs[col]))
+		}
+
+		return vals, nil
+	})
+
+	if _, err := tx.CopyFrom(ctx, tableName, copyCols, source); err != nil {
+		return nil, nil, errors.Wrapf(err, "error copying rows into table '%s'", table)
+	}
+
+	return nil, nil, tx.Commit(ctx)
+}
+
+// pgValue returns the value copied into PostgreSQL for the row value, NULL for missing values
+func pgValue(val RowValue, def *ColDef) interface{} {
+	if val != nil && (def.Type == TypDate || def.Type == TypTime) {
+		if v := val.ValTime(); v != nil {
+			return *v
+		}
+		return nil
+	}
+
+	return sqlValue(val, def)
+}
@@ -0,0 +1,410 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Strategies of the dedupe operation choosing the row kept for each key
+const (
+	DedupeFirst = "first"  // the first row of the key
+	DedupeLast  = "last"   // the last row of the key
+	DedupeMaxOf = "maxOf:" // the row with the greatest value in the column, eg. 'maxOf:updated_at'
+	DedupeMinOf = "minOf:" // the row with the lowest value in the column, eg. 'minOf:price'
+)
+
+var dedupeOp = Operation{
+	Name:   "dedupe",
+	OpFunc: opDedupe,
+	ArgDef: ArgDef{
+		"indexCols": reflect.TypeOf([]string{}),
+		"keep":      reflect.TypeOf(""),
+	},
+}
+
+// opDedupe keeps exactly one row per key, chosen according to the keep strategy. The
+// rows are kept unchanged, in the order their key first appears
+func opDedupe(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var cols []string
+	if cols, err = argSliceString(args, "indexCols"); err != nil {
+		return nil, nil, err
+	}
+
+	var keep string
+	if keep, err = argStringOpt(args, "keep", DedupeFirst); err != nil {
+		return nil, nil, err
+	}
+
+	// replace returns whether the row replaces the one kept so far for its key
+	var replace func(row, kept Row) bool
+
+	switch {
+	case keep == DedupeFirst:
+		replace = func(row, kept Row) bool { return false }
+	case keep == DedupeLast:
+		replace = func(row, kept Row) bool { return true }
+	case strings.HasPrefix(keep, DedupeMaxOf), strings.HasPrefix(keep, DedupeMinOf):
+		col := keep[len(DedupeMaxOf):]
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+
+		order := []string{"desc"}
+		if strings.HasPrefix(keep, DedupeMinOf) {
+			order = []string{"asc"}
+		}
+
+		replace = func(row, kept Row) bool { return rowLess(row, kept, defs, []string{col}, order) }
+	default:
+		return nil, nil, fmt.Errorf("keep must either be '%s', '%s', '%s<col>' or '%s<col>'", DedupeFirst, DedupeLast, DedupeMaxOf, DedupeMinOf)
+	}
+
+	var keys []string
+	kept := map[string]Row{}
+
+	for _, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		key := rowKey(row, cols)
+
+		keptRow, ok := kept[key]
+		if !ok {
+			keys = append(keys, key)
+			kept[key] = row
+			continue
+		}
+
+		if replace(row, keptRow) {
+			kept[key] = row
+		}
+	}
+
+	outRows := make([]Row, 0, len(keys))
+	for _, key := range keys {
+		outRows = append(outRows, kept[key])
+	}
+
+	return outRows, defs, nil
+}
+
+var limitOp = Operation{
+	Name:   "limit",
+	OpFunc: opLimit,
+	ArgDef: ArgDef{
+		"n":      reflect.TypeOf(1),
+		"offset": reflect.TypeOf(1),
+	},
+}
+
+// opLimit keeps at most n rows, after skipping the first offset rows
+func opLimit(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var n int
+	if n, err = argInt(args, "n"); err != nil {
+		return nil, nil, err
+	}
+
+	var offset int
+	if offset, err = argIntOpt(args, "offset", 0); err != nil {
+		return nil, nil, err
+	}
+
+	if n < 0 || offset < 0 {
+		return nil, nil, errors.New("n and offset cannot be negative")
+	}
+
+	start := offset
+	if start > len(*rows) {
+		start = len(*rows)
+	}
+
+	end := start + n
+	if end > len(*rows) {
+		end = len(*rows)
+	}
+
+	return append([]Row{}, (*rows)[start:end]...), defs, nil
+}
+
+var tailOp = Operation{
+	Name:   "tail",
+	OpFunc: opTail,
+	ArgDef: ArgDef{
+		"n": reflect.TypeOf(1),
+	},
+}
+
+// opTail keeps the last n rows
+func opTail(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	n, err := argInt(args, "n")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n < 0 {
+		return nil, nil, errors.New("n cannot be negative")
+	}
+
+	start := len(*rows) - n
+	if start < 0 {
+		start = 0
+	}
+
+	return append([]Row{}, (*rows)[start:]...), defs, nil
+}
+
+var sampleOp = Operation{
+	Name:   "sample",
+	OpFunc: opSample,
+	ArgDef: ArgDef{
+		"n":          reflect.TypeOf(1),
+		"percent":    reflect.TypeOf(""),
+		"seed":       reflect.TypeOf(1),
+		"stratifyBy": reflect.TypeOf(""),
+	},
+}
+
+// opSample keeps a random subset of n rows, or of a percentage of the rows. When stratified by
+// a column, the subset is taken among the rows of each value of the column. The rows are kept
+// in their original order
+func opSample(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var n int
+	if n, err = argIntOpt(args, "n", -1); err != nil {
+		return nil, nil, err
+	}
+
+	var percentStr string
+	if percentStr, err = argStringOpt(args, "percent", ""); err != nil {
+		return nil, nil, err
+	}
+
+	if (n < 0) == (percentStr == "") {
+		return nil, nil, errors.New("either the n or the percent argument must be provided")
+	}
+
+	var percent float64
+	if percentStr != "" {
+		if percent, err = strconv.ParseFloat(percentStr, 64); err != nil || percent < 0 || percent > 100 {
+			return nil, nil, fmt.Errorf("percent must be a number between 0 and 100, got '%s'", percentStr)
+		}
+	}
+
+	var seed int
+	if seed, err = argIntOpt(args, "seed", int(time.Now().UnixNano())); err != nil {
+		return nil, nil, err
+	}
+
+	var stratifyBy string
+	if stratifyBy, err = argStringOpt(args, "stratifyBy", ""); err != nil {
+		return nil, nil, err
+	}
+
+	// indexes of the rows of each stratum, all rows being in the same stratum if not stratified
+	var keys []string
+	strata := map[string][]int{}
+	for i, row := range *rows {
+		var key string
+		if stratifyBy != "" {
+			key = valStr(row, stratifyBy)
+		}
+
+		if _, ok := strata[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		strata[key] = append(strata[key], i)
+	}
+
+	rnd := rand.New(rand.NewSource(int64(seed)))
+
+	var picked []int
+	for _, key := range keys {
+		indexes := strata[key]
+
+		size := n
+		if percentStr != "" {
+			size = int(math.Round(float64(len(indexes)) * percent / 100))
+		}
+
+		if size > len(indexes) {
+			size = len(indexes)
+		}
+
+		rnd.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+
+		picked = append(picked, indexes[:size]...)
+	}
+
+	sort.Ints(picked)
+
+	outRows := make([]Row, 0, len(picked))
+	for _, i := range picked {
+		outRows = append(outRows, (*rows)[i])
+	}
+
+	return outRows, defs, nil
+}
+
+var fillDownOp = Operation{
+	Name:   "fillDown",
+	OpFunc: opFillDown,
+	ArgDef: ArgDef{
+		"cols":      reflect.TypeOf([]string{}),
+		"direction": reflect.TypeOf(""),
+	},
+}
+
+// opFillDown fills the empty values of the columns with the last non-empty value above them,
+// or below them when the direction is 'up'. Numeric and bool columns are never empty
+func opFillDown(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var direction string
+	if direction, err = argStringOpt(args, "direction", "down"); err != nil {
+		return nil, nil, err
+	}
+
+	if direction != "down" && direction != "up" {
+		return nil, nil, errors.New("direction must either be 'down' or 'up'")
+	}
+
+	outRows := append([]Row{}, *rows...)
+
+	indexes := make([]int, len(outRows))
+	for i := range indexes {
+		indexes[i] = i
+		if direction == "up" {
+			indexes[i] = len(outRows) - 1 - i
+		}
+	}
+
+	last := map[string]RowValue{}
+	for _, i := range indexes {
+		row := outRows[i]
+
+		var filled Row
+		for _, col := range cols {
+			val := row[col]
+			if val != nil && val.ValStr() != "" {
+				last[col] = val
+				continue
+			}
+
+			if last[col] == nil {
+				continue
+			}
+
+			// copying the row before changing it, as it may be used by other states
+			if filled == nil {
+				filled = Row{}
+				for name, v := range row {
+					filled[name] = v
+				}
+			}
+			filled[col] = last[col]
+		}
+
+		if filled != nil {
+			outRows[i] = filled
+		}
+	}
+
+	return outRows, defs, nil
+}
+
+var explodeOp = Operation{
+	Name:   "explode",
+	OpFunc: opExplode,
+	ArgDef: ArgDef{
+		"col":       reflect.TypeOf(""),
+		"sep":       reflect.TypeOf(""),
+		"skipEmpty": reflect.TypeOf(true),
+	},
+}
+
+// opExplode splits the multi-value cells of the column, eg. 'tag1;tag2', into one row per value,
+// the other columns being duplicated. Values are trimmed, and empty values, eg. in 'tag1;;tag2', are
+// left empty or skipped if skipEmpty is true. Rows with an empty cell are kept as they are
+func opExplode(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var col string
+	if col, err = argString(args, "col"); err != nil {
+		return nil, nil, err
+	}
+
+	var sep string
+	if sep, err = argStringOpt(args, "sep", ","); err != nil {
+		return nil, nil, err
+	}
+
+	var skipEmpty bool
+	if skipEmpty, err = argBoolOpt(args, "skipEmpty", false); err != nil {
+		return nil, nil, err
+	}
+
+	def, ok := defs[col]
+	if !ok {
+		return nil, nil, fmt.Errorf("column '%s' not found", col)
+	}
+
+	if sep == "" {
+		return nil, nil, errors.New("sep cannot be empty")
+	}
+
+	outRows := make([]Row, 0, len(*rows))
+	for i, row := range *rows {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		cell := valStr(row, col)
+		if cell == "" {
+			outRows = append(outRows, row)
+			continue
+		}
+
+		for _, part := range strings.Split(cell, sep) {
+			var val RowValue = emptyValue(def)
+			if strings.TrimSpace(part) == "" {
+				if skipEmpty {
+					continue
+				}
+			} else if val, err = NewValue(def, part); err != nil {
+				return nil, nil, errors.Wrapf(err, "error exploding column '%s' in row %d", col, i+1)
+			}
+
+			outRow := Row{}
+			for name, v := range row {
+				outRow[name] = v
+			}
+			outRow[col] = val
+
+			outRows = append(outRows, outRow)
+		}
+	}
+
+	return outRows, defs, nil
+}
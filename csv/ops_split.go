@@ -0,0 +1,100 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var splitFilesOp = Operation{
+	Name:   "splitFiles",
+	OpFunc: opSplitFiles,
+	ArgDef: ArgDef{
+		"filename":    reflect.TypeOf(""),
+		"cols":        reflect.TypeOf([]string{}),
+		"delimiter":   reflect.TypeOf(""),
+		"encoding":    reflect.TypeOf(""),
+		"quoteAll":    reflect.TypeOf(true),
+		"crlf":        reflect.TypeOf(true),
+		"header":      reflect.TypeOf(true),
+		"compression": reflect.TypeOf(""),
+	},
+}
+
+// placeholderRegexp matches the column placeholders of templates, eg. '{country}'
+var placeholderRegexp = regexp.MustCompile(`\{(\w+)\}`)
+
+// opSplitFiles writes the rows to one file per value of the columns referenced in the filename
+// template, eg. 'out/{country}.csv'. Directories of local files are created if needed
+func opSplitFiles(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var filename string
+	if filename, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	placeholders := placeholderRegexp.FindAllStringSubmatch(filename, -1)
+	if len(placeholders) == 0 {
+		return nil, nil, fmt.Errorf("filename '%s' must reference at least one column, eg. '{country}'", filename)
+	}
+
+	for _, p := range placeholders {
+		if _, ok := defs[p[1]]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' referenced in filename not found", p[1])
+		}
+	}
+
+	// the rows are partitioned by the name of the file they are written to
+	var fileNames []string
+	files := map[string][]Row{}
+	for _, row := range *rows {
+		name := placeholderRegexp.ReplaceAllStringFunc(filename, func(p string) string {
+			return filenameSafe(valStr(row, p[1:len(p)-1]))
+		})
+
+		if _, ok := files[name]; !ok {
+			fileNames = append(fileNames, name)
+		}
+
+		files[name] = append(files[name], row)
+	}
+
+	for _, name := range fileNames {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		if !strings.Contains(name, "://") {
+			if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if err := writeFile(ctx, env, name, files[name], cols, args); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// filenameSafe returns the value usable in a filename, with path separators replaced.
+// Empty values are replaced by '_'
+func filenameSafe(val string) string {
+	val = strings.NewReplacer("/", "_", "\\", "_").Replace(val)
+	if val == "" || val == "." || val == ".." {
+		return "_"
+	}
+
+	return val
+}
@@ -0,0 +1,200 @@
+package csv
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Behaviours of the toSqlite operation when the table already exists
+const (
+	IfExistsFail    = "fail"    // the operation fails
+	IfExistsReplace = "replace" // the table is dropped and created again
+	IfExistsAppend  = "append"  // the rows are inserted into the existing table
+)
+
+var toSqliteOp = Operation{
+	Name:   "toSqlite",
+	OpFunc: opToSqlite,
+	ArgDef: ArgDef{
+		"filename":  reflect.TypeOf(""),
+		"table":     reflect.TypeOf(""),
+		"cols":      reflect.TypeOf([]string{}),
+		"ifExists":  reflect.TypeOf(""),
+		"batchSize": reflect.TypeOf(1),
+	},
+}
+
+// opToSqlite creates a table from the definitions of the columns in a SQLite database file, and
+// inserts the rows into it. The rows are inserted by batches, each in its own transaction
+func opToSqlite(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName, table string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+	if table, err = argString(args, "table"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceStringOpt(args, "cols", sortedCols(defs)); err != nil {
+		return nil, nil, err
+	}
+
+	var ifExists string
+	if ifExists, err = argStringOpt(args, "ifExists", IfExistsFail); err != nil {
+		return nil, nil, err
+	}
+
+	if ifExists != IfExistsFail && ifExists != IfExistsReplace && ifExists != IfExistsAppend {
+		return nil, nil, fmt.Errorf("ifExists must either be '%s', '%s' or '%s'", IfExistsFail, IfExistsReplace, IfExistsAppend)
+	}
+
+	var batchSize int
+	if batchSize, err = argIntOpt(args, "batchSize", 1000); err != nil {
+		return nil, nil, err
+	}
+	if batchSize < 1 {
+		return nil, nil, errors.New("batchSize must be greater than 0")
+	}
+
+	var colDefs []string
+	var quotedCols []string
+	for _, col := range cols {
+		def, ok := defs[col]
+		if !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+
+		quotedCols = append(quotedCols, quoteIdent(col))
+		colDefs = append(colDefs, quoteIdent(col)+" "+sqliteType(def.Type))
+	}
+
+	db, err := sql.Open("sqlite", fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	if ifExists == IfExistsReplace {
+		if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdent(table)); err != nil {
+			return nil, nil, errors.Wrapf(err, "error dropping table '%s'", table)
+		}
+	}
+
+	create := "CREATE TABLE "
+	if ifExists == IfExistsAppend {
+		create += "IF NOT EXISTS "
+	}
+
+	create += fmt.Sprintf("%s (%s)", quoteIdent(table), strings.Join(colDefs, ", "))
+	if _, err := db.ExecContext(ctx, create); err != nil {
+		return nil, nil, errors.Wrapf(err, "error creating table '%s'", table)
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(table), strings.Join(quotedCols, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "))
+
+	for from := 0; from < len(*rows); from += batchSize {
+		to := from + batchSize
+		if to > len(*rows) {
+			to = len(*rows)
+		}
+
+		if err := insertBatch(ctx, db, insert, (*rows)[from:to], defs, cols); err != nil {
+			return nil, nil, errors.Wrapf(err, "error inserting rows %d to %d into table '%s'", from+1, to, table)
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// insertBatch inserts the rows with the insert statement in a single transaction
+func insertBatch(ctx context.Context, db *sql.DB, insert string, rows []Row, defs ValueDefs, cols []string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		var vals []interface{}
+		for _, col := range cols {
+			vals = append(vals, sqlValue(row[col], defs[col]))
+		}
+
+		if _, err := stmt.ExecContext(ctx, vals...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sqliteType returns the SQLite type of the columns of the given type
+func sqliteType(typ string) string {
+	switch typ {
+	case TypInt, TypBool:
+		return "INTEGER"
+	case TypFloat:
+		return "REAL"
+	}
+
+	return "TEXT"
+}
+
+// sqlValue returns the value inserted into a database for the row value, NULL for missing
+// values. Dates and timestamps are inserted as ISO 8601 strings
+func sqlValue(val RowValue, def *ColDef) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	switch def.Type {
+	case TypInt:
+		if v := val.ValInt(); v != nil {
+			return *v
+		}
+		return nil
+	case TypFloat:
+		if v := val.ValFloat(); v != nil {
+			return *v
+		}
+		return nil
+	case TypBool:
+		if v := val.ValBool(); v != nil {
+			return *v
+		}
+		return nil
+	case TypDate, TypTime:
+		v := val.ValTime()
+		if v == nil {
+			return nil
+		}
+		if def.Type == TypDate {
+			return v.Format("2006-01-02")
+		}
+		return v.Format(time.RFC3339)
+	}
+
+	return val.ValStr()
+}
+
+// quoteIdent quotes an SQL identifier such as a table or a column name
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,198 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+var describeOp = Operation{
+	Name:   "describe",
+	OpFunc: opDescribe,
+	ArgDef: ArgDef{
+		"cols": reflect.TypeOf([]string{}),
+	},
+}
+
+// describeCols are the columns of the rows returned by the describe operation
+var describeCols = []*ColDef{
+	{Name: "column", Type: TypStr, Dynamic: true},
+	{Name: "type", Type: TypStr, Dynamic: true},
+	{Name: "count", Type: TypInt, Dynamic: true},
+	{Name: "nulls", Type: TypInt, Dynamic: true},
+	{Name: "distinct", Type: TypInt, Dynamic: true},
+	{Name: "min", Type: TypStr, Dynamic: true},
+	{Name: "max", Type: TypStr, Dynamic: true},
+	{Name: "mean", Type: TypStr, Dynamic: true},
+	{Name: "stddev", Type: TypStr, Dynamic: true},
+}
+
+// opDescribe profiles the columns, returning one row per column with its number of values,
+// empty values and distinct values, its min and max values, as well as the mean and the
+// standard deviation of numeric columns
+func opDescribe(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	cols, err := argSliceStringOpt(args, "cols", sortedCols(defs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := Header{}
+	outDefs := ValueDefs{}
+	for i, def := range describeCols {
+		header[i] = def
+		outDefs[def.Name] = def
+	}
+
+	var outRows []Row
+	for _, col := range cols {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		def, ok := defs[col]
+		if !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+
+		outRow, err := NewRow(header, describeCol(*rows, defs, def))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+// describeCol returns the statistics of the column as a describe record
+func describeCol(rows []Row, defs ValueDefs, def *ColDef) []string {
+	col := def.Name
+	numeric := def.Type == TypInt || def.Type == TypFloat
+
+	var nulls int
+	var minRow, maxRow Row
+	var sum, sumSq float64
+	distinct := map[string]bool{}
+
+	for _, row := range rows {
+		val := valStr(row, col)
+		if val == "" || (numeric && row[col].ValFloat() == nil) {
+			nulls++
+			continue
+		}
+
+		distinct[val] = true
+
+		if minRow == nil || rowLess(row, minRow, defs, []string{col}, []string{"asc"}) {
+			minRow = row
+		}
+		if maxRow == nil || rowLess(row, maxRow, defs, []string{col}, []string{"desc"}) {
+			maxRow = row
+		}
+
+		if numeric {
+			f := *row[col].ValFloat()
+			sum += f
+			sumSq += f * f
+		}
+	}
+
+	count := len(rows) - nulls
+	rec := []string{col, def.Type, strconv.Itoa(count), strconv.Itoa(nulls), strconv.Itoa(len(distinct)), "", "", "", ""}
+
+	if minRow != nil {
+		rec[5], rec[6] = valStr(minRow, col), valStr(maxRow, col)
+	}
+
+	if numeric && count > 0 {
+		mean := sum / float64(count)
+		variance := sumSq/float64(count) - mean*mean
+
+		rec[7] = strconv.FormatFloat(mean, 'f', -1, 64)
+		rec[8] = strconv.FormatFloat(math.Sqrt(math.Max(variance, 0)), 'f', -1, 64)
+	}
+
+	return rec
+}
+
+var rowCountOp = Operation{
+	Name:   "rowCount",
+	OpFunc: opRowCount,
+	ArgDef: ArgDef{
+		"col":      reflect.TypeOf(""),
+		"filename": reflect.TypeOf(""),
+	},
+}
+
+// opRowCount returns a single row holding the number of rows, which is also written to the file
+// if provided. '-' writes to the output of the run
+func opRowCount(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var col string
+	if col, err = argStringOpt(args, "col", "count"); err != nil {
+		return nil, nil, err
+	}
+
+	var fileName string
+	if fileName, err = argStringOpt(args, "filename", ""); err != nil {
+		return nil, nil, err
+	}
+
+	def := &ColDef{Name: col, Type: TypInt, Dynamic: true}
+
+	countRow, err := NewRow(Header{0: def}, []string{strconv.Itoa(len(*rows))})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outRows := []Row{countRow}
+	if fileName != "" {
+		if err := writeFile(ctx, env, fileName, outRows, []string{col}, args); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return outRows, ValueDefs{col: def}, nil
+}
+
+var assertCountOp = Operation{
+	Name:   "assertCount",
+	OpFunc: opAssertCount,
+	ArgDef: ArgDef{
+		"min": reflect.TypeOf(1),
+		"max": reflect.TypeOf(1),
+	},
+}
+
+// opAssertCount fails the run if the number of rows is below min or above max. The rows are
+// returned unchanged
+func opAssertCount(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var min, max int
+	if min, err = argIntOpt(args, "min", -1); err != nil {
+		return nil, nil, err
+	}
+	if max, err = argIntOpt(args, "max", -1); err != nil {
+		return nil, nil, err
+	}
+
+	if min < 0 && max < 0 {
+		return nil, nil, errors.New("at least one of the min or max arguments must be provided")
+	}
+
+	count := len(*rows)
+	if min >= 0 && count < min {
+		return nil, nil, fmt.Errorf("expected at least %d rows, got %d", min, count)
+	}
+	if max >= 0 && count > max {
+		return nil, nil, fmt.Errorf("expected at most %d rows, got %d", max, count)
+	}
+
+	return *rows, defs, nil
+}
@@ -0,0 +1,94 @@
+package csv
+
+import (
+	"bufio"
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"text/template"
+)
+
+var toTemplateOp = Operation{
+	Name:   "toTemplate",
+	OpFunc: opToTemplate,
+	ArgDef: ArgDef{
+		"filename":     reflect.TypeOf(""),
+		"template":     reflect.TypeOf(""),
+		"templateFile": reflect.TypeOf(""),
+		"prefix":       reflect.TypeOf(""),
+		"suffix":       reflect.TypeOf(""),
+		"compression":  reflect.TypeOf(""),
+	},
+}
+
+// opToTemplate renders each row with a Go text/template, given inline or read from a file, and
+// writes the results one after the other between the optional prefix and suffix, eg. to generate
+// SQL or XML. The values of the row are available by column name, eg. '{{.filename}}'.
+// '-' writes to the output of the run
+func opToTemplate(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return nil, nil, err
+	}
+
+	var text, templateFile string
+	if text, err = argStringOpt(args, "template", ""); err != nil {
+		return nil, nil, err
+	}
+	if templateFile, err = argStringOpt(args, "templateFile", ""); err != nil {
+		return nil, nil, err
+	}
+
+	if (text == "") == (templateFile == "") {
+		return nil, nil, errors.New("either the template or the templateFile argument must be provided")
+	}
+
+	if templateFile != "" {
+		b, err := ioutil.ReadFile(templateFile)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error reading template file '%s'", templateFile)
+		}
+		text = string(b)
+	}
+
+	tpl, err := template.New("toTemplate").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid template")
+	}
+
+	var prefix, suffix string
+	if prefix, err = argStringOpt(args, "prefix", ""); err != nil {
+		return nil, nil, err
+	}
+	if suffix, err = argStringOpt(args, "suffix", ""); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, nil, writeOutput(ctx, env, fileName, args, func(out io.Writer) error {
+		w := bufio.NewWriter(out)
+		w.WriteString(prefix)
+
+		for i, row := range *rows {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			data := map[string]string{}
+			for col, val := range row {
+				data[col] = val.ValStr()
+			}
+
+			if err := tpl.Execute(w, data); err != nil {
+				return errors.Wrapf(err, "error rendering row %d", i+1)
+			}
+		}
+
+		w.WriteString(suffix)
+
+		return w.Flush()
+	})
+}
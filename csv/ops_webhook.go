@@ -0,0 +1,398 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var webhookOp = Operation{
+	Name:   "webhook",
+	OpFunc: opWebhook,
+	ArgDef: ArgDef{
+		"url":         reflect.TypeOf(""),
+		"cols":        reflect.TypeOf([]string{}),
+		"outCols":     reflect.TypeOf([]string{}),
+		"statusCol":   reflect.TypeOf(""),
+		"headers":     reflect.TypeOf([]string{}),
+		"batchSize":   reflect.TypeOf(1),
+		"concurrency": reflect.TypeOf(1),
+		"retries":     reflect.TypeOf(1),
+		"backoff":     reflect.TypeOf(""),
+		"onError":     reflect.TypeOf(""),
+	},
+}
+
+// webhookHeader is a request header whose value is a template rendered
+// against the row values
+type webhookHeader struct {
+	name  string
+	value *template.Template
+}
+
+// parseWebhookHeaders parses the request headers formatted as 'Name: value', the value
+// being a template rendered against the row values
+func parseWebhookHeaders(headersStr []string) ([]webhookHeader, error) {
+	var headers []webhookHeader
+	for _, h := range headersStr {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("header '%s' must be formatted as 'Name: value'", h)
+		}
+
+		tpl, err := template.New(kv[0]).Parse(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template in header '%s'", kv[0])
+		}
+
+		headers = append(headers, webhookHeader{name: strings.TrimSpace(kv[0]), value: tpl})
+	}
+
+	return headers, nil
+}
+
+// opWebhook POSTs rows as JSON to the given url, either one object per row or
+// arrays of batchSize rows, and stores the response status code in statusCol. The rows
+// returned hold the outCols columns and statusCol
+func opWebhook(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var url string
+	if url, err = argString(args, "url"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var outCols []string
+	if outCols, err = argSliceString(args, "outCols"); err != nil {
+		return nil, nil, err
+	}
+
+	var statusCol string
+	if statusCol, err = argString(args, "statusCol"); err != nil {
+		return nil, nil, err
+	}
+
+	var headersStr []string
+	if headersStr, err = argSliceStringOpt(args, "headers", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var batchSize int
+	if batchSize, err = argIntOpt(args, "batchSize", 1); err != nil {
+		return nil, nil, err
+	}
+
+	var concurrency int
+	if concurrency, err = argIntOpt(args, "concurrency", 1); err != nil {
+		return nil, nil, err
+	}
+
+	var retries int
+	if retries, err = argIntOpt(args, "retries", 0); err != nil {
+		return nil, nil, err
+	}
+
+	var backoffStr string
+	if backoffStr, err = argStringOpt(args, "backoff", "1s"); err != nil {
+		return nil, nil, err
+	}
+
+	backoff, err := time.ParseDuration(backoffStr)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid backoff")
+	}
+
+	var onError string
+	if onError, err = argStringOpt(args, "onError", OnErrorAbort); err != nil {
+		return nil, nil, err
+	}
+	if onError != OnErrorAbort && onError != OnErrorSkip {
+		return nil, nil, fmt.Errorf("onError must either be '%s' or '%s'", OnErrorAbort, OnErrorSkip)
+	}
+
+	if batchSize < 1 || concurrency < 1 {
+		return nil, nil, errors.New("batchSize and concurrency must be greater than 0")
+	}
+
+	headers, err := parseWebhookHeaders(headersStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, col := range append(append([]string{}, cols...), outCols...) {
+		if _, ok := defs[col]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", col)
+		}
+	}
+
+	// the output rows are copies of the rows projected to outCols, so that the status column
+	// is not added to the rows of the other states
+	cpRows, outDefs, err := projectColumns(ctx, *rows, defs, outCols)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statusColDef := &ColDef{
+		Name:    statusCol,
+		Type:    TypInt,
+		Dynamic: true,
+	}
+	outDefs[statusCol] = statusColDef
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var ch = make(chan int, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for start := 0; start < len(cpRows); start += batchSize {
+		end := start + batchSize
+		if end > len(cpRows) {
+			end = len(cpRows)
+		}
+
+		wg.Add(1)
+		go func(batch []Row, outBatch []Row, start int) {
+			ch <- 1
+			defer func() {
+				<-ch
+				wg.Done()
+			}()
+
+			status, err := postWebhook(ctx, client, url, headers, batch, defs, cols, batchSize > 1, retries, backoff)
+			if err != nil {
+				if onError == OnErrorSkip {
+					logrus.Warnf("webhook: failed posting rows from %d: %s", start, err)
+				} else {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = errors.Wrapf(err, "error posting rows from %d", start)
+					}
+					mu.Unlock()
+				}
+			}
+
+			for _, r := range outBatch {
+				r[statusCol], _ = NewValue(statusColDef, strconv.Itoa(status))
+			}
+		}((*rows)[start:end], cpRows[start:end], start)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	return cpRows, outDefs, nil
+}
+
+// postWebhook sends the batch of rows and returns the response status code. Requests
+// failing with a network error, a 429 or a 5xx status are retried with an exponential backoff
+func postWebhook(ctx context.Context, client *http.Client, url string, headers []webhookHeader, batch []Row, defs ValueDefs, cols []string, asArray bool, retries int, backoff time.Duration) (int, error) {
+	var payload interface{}
+	if asArray {
+		var objs []RowObject
+		for _, r := range batch {
+			objs = append(objs, r.Object(defs, cols))
+		}
+		payload = objs
+	} else {
+		payload = batch[0].Object(defs, cols)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	// headers are rendered against the first row of the batch
+	tplData := map[string]string{}
+	for col, val := range batch[0] {
+		tplData[col] = val.ValStr()
+	}
+
+	var status int
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		for _, h := range headers {
+			var val bytes.Buffer
+			if err := h.value.Execute(&val, tplData); err != nil {
+				return 0, errors.Wrapf(err, "error rendering header '%s'", h.name)
+			}
+			req.Header.Set(h.name, val.String())
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			status = resp.StatusCode
+
+			if status < 300 {
+				return status, nil
+			}
+
+			err = fmt.Errorf("unexpected status %d", status)
+			if status != http.StatusTooManyRequests && status < 500 {
+				return status, err
+			}
+		}
+
+		if attempt >= retries {
+			return status, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(backoff * time.Duration(1<<uint(attempt))):
+		}
+	}
+}
+
+var httpPostOp = Operation{
+	Name:   "httpPost",
+	OpFunc: opHttpPost,
+	ArgDef: ArgDef{
+		"url":         reflect.TypeOf(""),
+		"cols":        reflect.TypeOf([]string{}),
+		"headers":     reflect.TypeOf([]string{}),
+		"batchSize":   reflect.TypeOf(1),
+		"concurrency": reflect.TypeOf(1),
+		"retries":     reflect.TypeOf(1),
+		"backoff":     reflect.TypeOf(""),
+		"onError":     reflect.TypeOf(""),
+	},
+}
+
+// opHttpPost POSTs the rows to the given url as JSON arrays of batchSize rows, eg. to feed a
+// REST ingest API. Unlike webhook, the responses are not kept
+func opHttpPost(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var url string
+	if url, err = argString(args, "url"); err != nil {
+		return nil, nil, err
+	}
+
+	var cols []string
+	if cols, err = argSliceString(args, "cols"); err != nil {
+		return nil, nil, err
+	}
+
+	var headersStr []string
+	if headersStr, err = argSliceStringOpt(args, "headers", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var batchSize int
+	if batchSize, err = argIntOpt(args, "batchSize", 100); err != nil {
+		return nil, nil, err
+	}
+
+	var concurrency int
+	if concurrency, err = argIntOpt(args, "concurrency", 1); err != nil {
+		return nil, nil, err
+	}
+
+	var retries int
+	if retries, err = argIntOpt(args, "retries", 3); err != nil {
+		return nil, nil, err
+	}
+
+	var backoffStr string
+	if backoffStr, err = argStringOpt(args, "backoff", "1s"); err != nil {
+		return nil, nil, err
+	}
+
+	backoff, err := time.ParseDuration(backoffStr)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "invalid backoff")
+	}
+
+	var onError string
+	if onError, err = argStringOpt(args, "onError", OnErrorAbort); err != nil {
+		return nil, nil, err
+	}
+	if onError != OnErrorAbort && onError != OnErrorSkip {
+		return nil, nil, fmt.Errorf("onError must either be '%s' or '%s'", OnErrorAbort, OnErrorSkip)
+	}
+
+	if batchSize < 1 || concurrency < 1 {
+		return nil, nil, errors.New("batchSize and concurrency must be greater than 0")
+	}
+
+	headers, err := parseWebhookHeaders(headersStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var ch = make(chan int, concurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for start := 0; start < len(*rows); start += batchSize {
+		end := start + batchSize
+		if end > len(*rows) {
+			end = len(*rows)
+		}
+
+		wg.Add(1)
+		go func(batch []Row, start int) {
+			ch <- 1
+			defer func() {
+				<-ch
+				wg.Done()
+			}()
+
+			if _, err := postWebhook(ctx, client, url, headers, batch, defs, cols, true, retries, backoff); err != nil {
+				if onError == OnErrorSkip {
+					logrus.Warnf("httpPost: failed posting rows from %d: %s", start, err)
+					return
+				}
+
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = errors.Wrapf(err, "error posting rows from %d", start)
+				}
+				mu.Unlock()
+			}
+		}((*rows)[start:end], start)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, nil, firstErr
+}
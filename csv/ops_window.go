@@ -0,0 +1,199 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Functions of the window operation
+const (
+	WindowRowNumber  = "rowNumber"  // position of the row in its partition, starting at 1
+	WindowRank       = "rank"       // rank of the row in its partition, with gaps after ties
+	WindowDenseRank  = "denseRank"  // rank of the row in its partition, without gaps after ties
+	WindowLag        = "lag"        // value of the column offset rows before in the partition
+	WindowLead       = "lead"       // value of the column offset rows after in the partition
+	WindowRunningSum = "runningSum" // sum of the values of the column up to the row in the partition
+)
+
+var windowOp = Operation{
+	Name:   "window",
+	OpFunc: opWindow,
+	ArgDef: ArgDef{
+		"col":         reflect.TypeOf(""),
+		"function":    reflect.TypeOf(""),
+		"fromCol":     reflect.TypeOf(""),
+		"partitionBy": reflect.TypeOf([]string{}),
+		"orderBy":     reflect.TypeOf([]string{}),
+		"order":       reflect.TypeOf([]string{}),
+		"offset":      reflect.TypeOf(1),
+	},
+}
+
+// opWindow adds a column computed by the window function over the rows of each partition, sorted
+// by the orderBy columns. The rows are kept in their original order
+func opWindow(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+	var err error
+
+	var col, fn, fromCol string
+	if col, err = argString(args, "col"); err != nil {
+		return nil, nil, err
+	}
+	if fn, err = argString(args, "function"); err != nil {
+		return nil, nil, err
+	}
+	if fromCol, err = argStringOpt(args, "fromCol", ""); err != nil {
+		return nil, nil, err
+	}
+
+	var partitionBy, orderBy, order []string
+	if partitionBy, err = argSliceStringOpt(args, "partitionBy", nil); err != nil {
+		return nil, nil, err
+	}
+	if orderBy, err = argSliceStringOpt(args, "orderBy", nil); err != nil {
+		return nil, nil, err
+	}
+	if order, err = argSliceStringOpt(args, "order", nil); err != nil {
+		return nil, nil, err
+	}
+
+	var offset int
+	if offset, err = argIntOpt(args, "offset", 1); err != nil {
+		return nil, nil, err
+	}
+
+	for _, c := range append(append([]string{}, partitionBy...), orderBy...) {
+		if _, ok := defs[c]; !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found", c)
+		}
+	}
+
+	// the order defaults to ascending for all columns
+	if len(order) == 0 {
+		for range orderBy {
+			order = append(order, "asc")
+		}
+	}
+
+	if len(order) != len(orderBy) {
+		return nil, nil, errors.New("number of items in 'order' must be equal to number of items in 'orderBy'")
+	}
+
+	def := &ColDef{Name: col, Type: TypInt, Dynamic: true}
+
+	switch fn {
+	case WindowRowNumber, WindowRank, WindowDenseRank:
+	case WindowLag, WindowLead, WindowRunningSum:
+		fromDef, ok := defs[fromCol]
+		if !ok {
+			return nil, nil, fmt.Errorf("column '%s' not found, fromCol is required by '%s'", fromCol, fn)
+		}
+
+		def = &ColDef{Name: col, Type: fromDef.Type, Layout: fromDef.Layout, Dynamic: true}
+
+		if fn == WindowRunningSum {
+			if fromDef.Type != TypInt && fromDef.Type != TypFloat {
+				return nil, nil, fmt.Errorf("column '%s' must be an int or a float to compute its running sum", fromCol)
+			}
+			def.Type = TypFloat
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported window function '%s'", fn)
+	}
+
+	// indexes of the rows of each partition
+	var keys []string
+	partitions := map[string][]int{}
+	for i, row := range *rows {
+		key := rowKey(row, partitionBy)
+
+		if _, ok := partitions[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		partitions[key] = append(partitions[key], i)
+	}
+
+	values := make([]string, len(*rows))
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		indexes := partitions[key]
+		sort.SliceStable(indexes, func(i, j int) bool {
+			return rowLess((*rows)[indexes[i]], (*rows)[indexes[j]], defs, orderBy, order)
+		})
+
+		windowValues(*rows, indexes, values, defs, fn, fromCol, orderBy, order, offset)
+	}
+
+	outDefs := ValueDefs{}
+	for name, d := range defs {
+		outDefs[name] = d
+	}
+	outDefs[col] = def
+
+	outRows := make([]Row, 0, len(*rows))
+	for i, row := range *rows {
+		// like SQL's NULL, lag and lead are empty when there is no row at the offset, rather than 0
+		var val RowValue = emptyValue(def)
+		if values[i] != "" || (fn != WindowLag && fn != WindowLead) {
+			if val, err = NewValue(def, values[i]); err != nil {
+				return nil, nil, errors.Wrapf(err, "error computing column '%s' in row %d", col, i+1)
+			}
+		}
+
+		outRow := Row{}
+		for name, v := range row {
+			outRow[name] = v
+		}
+		outRow[col] = val
+
+		outRows = append(outRows, outRow)
+	}
+
+	return outRows, outDefs, nil
+}
+
+// windowValues sets the values of the window function for the rows of a partition, given by
+// their sorted indexes
+func windowValues(rows []Row, indexes []int, values []string, defs ValueDefs, fn, fromCol string, orderBy, order []string, offset int) {
+	var rank, denseRank int
+	var sum float64
+
+	for pos, i := range indexes {
+		switch fn {
+		case WindowRowNumber:
+			values[i] = strconv.Itoa(pos + 1)
+		case WindowRank, WindowDenseRank:
+			// ties share the rank of the first row of the tie
+			if pos == 0 || rowLess(rows[indexes[pos-1]], rows[i], defs, orderBy, order) {
+				rank = pos + 1
+				denseRank++
+			}
+
+			values[i] = strconv.Itoa(rank)
+			if fn == WindowDenseRank {
+				values[i] = strconv.Itoa(denseRank)
+			}
+		case WindowLag, WindowLead:
+			other := pos - offset
+			if fn == WindowLead {
+				other = pos + offset
+			}
+
+			if other >= 0 && other < len(indexes) {
+				values[i] = valStr(rows[indexes[other]], fromCol)
+			}
+		case WindowRunningSum:
+			if v := rows[i][fromCol].ValFloat(); v != nil {
+				sum += *v
+			}
+			values[i] = strconv.FormatFloat(sum, 'f', -1, 64)
+		}
+	}
+}
@@ -1,10 +1,12 @@
 package csv
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/robertkrimen/otto"
 	"path/filepath"
+	"sort"
 	"strconv"
 
 	"reflect"
@@ -13,7 +15,7 @@ import (
 
 // ParseFunc is the definition of the function used to run the parser
 // for the given arguments
-type ParseFunc func(args FuncArgs) (string, error)
+type ParseFunc func(ctx context.Context, args FuncArgs) (string, error)
 
 // ColParser is the column parser as defined in the loaded configuration
 type ColParser struct {
@@ -29,7 +31,7 @@ type ParserI interface {
 	Name() string
 	Parser() ParseFunc
 	ArgDef() ArgDef
-	Parse(args FuncArgs) (string, error)
+	Parse(ctx context.Context, args FuncArgs) (string, error)
 }
 
 // JsParserI is the Javascript parser interface which also inherits from
@@ -78,9 +80,30 @@ func validateParserArgType(defType reflect.Type, arg ParserArg) error {
 		return fmt.Errorf("type must be 'array'")
 	}
 
+	if (len(arg.Map) > 0) != (defType.Kind() == reflect.Map) {
+		if len(arg.Map) > 0 {
+			return errors.New("type must either be 'self', 'col', 'val' or 'array', not 'map'")
+		}
+		return errors.New("type must be 'map'")
+	}
+
 	return nil
 }
 
+// Parsers returns all available parsers sorted by name
+func Parsers() []ParserI {
+	var list []ParserI
+	for _, parser := range parsers {
+		list = append(list, parser)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+
+	return list
+}
+
 // AddParsers adds given parsers to the list
 func AddParsers(parsersList ...ParserI) error {
 	for _, parser := range parsersList {
@@ -100,6 +123,9 @@ func AddParsers(parsersList ...ParserI) error {
 	return nil
 }
 
+// errJsInterrupted is the value the javascript vm panics with when the context is cancelled
+var errJsInterrupted = errors.New("js parser interrupted")
+
 // NewJSParser creates a javascript parser from a javascript file
 func NewJSParser(filename string) (JsParserI, error) {
 	vm := otto.New()
@@ -157,9 +183,33 @@ func NewJSParser(filename string) (JsParserI, error) {
 	}
 
 	// implement the ParserFunc function
-	parser.parser = func(args FuncArgs) (string, error) {
+	parser.parser = func(ctx context.Context, args FuncArgs) (output string, err error) {
 		vm := otto.New()
 
+		// interrupting the script if the context is cancelled while it runs
+		vm.Interrupt = make(chan func(), 1)
+		done := make(chan struct{})
+		defer close(done)
+
+		go func() {
+			select {
+			case <-ctx.Done():
+				vm.Interrupt <- func() {
+					panic(errJsInterrupted)
+				}
+			case <-done:
+			}
+		}()
+
+		defer func() {
+			if r := recover(); r != nil {
+				if r != errJsInterrupted {
+					panic(r)
+				}
+				output, err = "", ctx.Err()
+			}
+		}()
+
 		// Making sure that the provided argument values match the defined required types
 		for arg, typ := range parser.args {
 			val, ok := args[arg]
@@ -180,12 +230,12 @@ func NewJSParser(filename string) (JsParserI, error) {
 		}
 
 		// We expect the string variable 'output' in the js script to be defined and ready for extraction
-		output, err := vm.Get("output")
+		outputVal, err := vm.Get("output")
 		if err != nil {
 			return "", err
 		}
 
-		return output.String(), nil
+		return outputVal.String(), nil
 	}
 
 	return parser, nil
@@ -199,6 +249,16 @@ type Parser struct {
 	args   ArgDef    // arguments are values to be parsed
 }
 
+// NewParser creates a parser from its name, parse function and arguments definition.
+// It is mainly intended for parsers defined outside of this package, eg. in plugins
+func NewParser(name string, parser ParseFunc, args ArgDef) *Parser {
+	return &Parser{
+		name:   name,
+		parser: parser,
+		args:   args,
+	}
+}
+
 // Name returns the name of the parser
 func (p *Parser) Name() string {
 	return p.name
@@ -215,8 +275,8 @@ func (p *Parser) ArgDef() ArgDef {
 }
 
 // Parse runs the parser
-func (p *Parser) Parse(args FuncArgs) (string, error) {
-	return p.parser(args)
+func (p *Parser) Parse(ctx context.Context, args FuncArgs) (string, error) {
+	return p.parser(ctx, args)
 }
 
 // JsParser is a parser enabling javascript code to do the parsing
@@ -243,8 +303,8 @@ func (jp *JsParser) ArgDef() ArgDef {
 }
 
 // Parse runs the parser
-func (jp *JsParser) Parse(args FuncArgs) (string, error) {
-	return jp.parser(args)
+func (jp *JsParser) Parse(ctx context.Context, args FuncArgs) (string, error) {
+	return jp.parser(ctx, args)
 }
 
 // Script returns the compiled javascript script used by the parser
@@ -306,7 +366,11 @@ func argBool(args FuncArgs, argName string) (bool, error) {
 		return false, fmt.Errorf("'%s' must be a boolean", argName)
 	}
 
-	vBool, _ = strBool[vS]
+	// parsed like the values of the bool columns, as when the configuration is validated
+	if vBool, ok = parseBool(vS); !ok {
+		return false, fmt.Errorf("'%s' must be a boolean", argName)
+	}
+
 	return vBool, nil
 }
 
@@ -323,3 +387,39 @@ func argSliceString(args FuncArgs, argName string) ([]string, error) {
 
 	return vS, nil
 }
+
+// argStringOpt returns the string argument, or def if it was not provided
+func argStringOpt(args FuncArgs, argName string, def string) (string, error) {
+	if _, ok := args[argName]; !ok {
+		return def, nil
+	}
+
+	return argString(args, argName)
+}
+
+// argIntOpt returns the integer argument, or def if it was not provided
+func argIntOpt(args FuncArgs, argName string, def int) (int, error) {
+	if _, ok := args[argName]; !ok {
+		return def, nil
+	}
+
+	return argInt(args, argName)
+}
+
+// argBoolOpt returns the boolean argument, or def if it was not provided
+func argBoolOpt(args FuncArgs, argName string, def bool) (bool, error) {
+	if _, ok := args[argName]; !ok {
+		return def, nil
+	}
+
+	return argBool(args, argName)
+}
+
+// argSliceStringOpt returns the slice of strings argument, or def if it was not provided
+func argSliceStringOpt(args FuncArgs, argName string, def []string) ([]string, error) {
+	if _, ok := args[argName]; !ok {
+		return def, nil
+	}
+
+	return argSliceString(args, argName)
+}
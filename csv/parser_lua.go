@@ -0,0 +1,178 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+)
+
+// LuaParserI is the Lua parser interface which also inherits from
+// ParserI interface's behaviours
+type LuaParserI interface {
+	ParserI
+	Script() string
+}
+
+// NewLuaParser creates a lua parser from a lua file. Like javascript parsers, the
+// script declares its arguments in the global 'args' table and returns its result
+// in the global 'output' variable
+func NewLuaParser(filename string) (LuaParserI, error) {
+	script, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk, err := parse.Parse(bytes.NewReader(script), filename)
+	if err != nil {
+		return nil, err
+	}
+
+	proto, err := lua.Compile(chunk, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// running the script without checking for errors, all we want is the required args list
+	L := lua.NewState()
+	defer L.Close()
+
+	L.Push(L.NewFunctionFromProto(proto))
+	L.PCall(0, lua.MultRet, nil)
+
+	parser := &LuaParser{
+		name:   filepath.Base(filename),
+		script: string(script),
+	}
+
+	// checking if we have required arguments
+	if reqVals := L.GetGlobal("args"); reqVals != lua.LNil {
+		args, ok := reqVals.(*lua.LTable)
+		if !ok {
+			return nil, fmt.Errorf("lua error: 'args' must be a table in '%s'", filename)
+		}
+
+		parserArgs := ArgDef{}
+
+		// we translate the required arguments type from the lua args definition to their go type
+		var typErr error
+		args.ForEach(func(arg lua.LValue, typ lua.LValue) {
+			switch typ.String() {
+			case "string":
+				parserArgs[arg.String()] = reflect.TypeOf("")
+			case "array":
+				parserArgs[arg.String()] = reflect.TypeOf([]interface{}{})
+			case "object":
+				parserArgs[arg.String()] = reflect.TypeOf(map[string]interface{}{})
+			case "bool":
+				parserArgs[arg.String()] = reflect.TypeOf(true)
+			default:
+				typErr = fmt.Errorf("type '%s' is not supported in '%s'", typ.String(), filename)
+			}
+		})
+		if typErr != nil {
+			return nil, typErr
+		}
+
+		parser.args = parserArgs
+	}
+
+	// implement the ParserFunc function
+	parser.parser = func(ctx context.Context, args FuncArgs) (string, error) {
+		L := lua.NewState()
+		defer L.Close()
+
+		L.SetContext(ctx)
+
+		// Making sure that the provided argument values match the defined required types
+		for arg, typ := range parser.args {
+			val, ok := args[arg]
+			if !ok {
+				return "", fmt.Errorf("arg '%s' required but missing", arg)
+			}
+
+			valType := reflect.TypeOf(val)
+			if typ != valType {
+				return "", fmt.Errorf("unexpected argument type. Expected '%s', got '%s' in '%s'", typ.String(), valType.String(), filename)
+			}
+
+			L.SetGlobal(arg, toLuaValue(L, val))
+		}
+
+		L.Push(L.NewFunctionFromProto(proto))
+		if err := L.PCall(0, lua.MultRet, nil); err != nil {
+			return "", err
+		}
+
+		// We expect the string variable 'output' in the lua script to be defined and ready for extraction
+		output := L.GetGlobal("output")
+		if output == lua.LNil {
+			return "", fmt.Errorf("lua error: 'output' is not defined in '%s'", filename)
+		}
+
+		return output.String(), nil
+	}
+
+	return parser, nil
+}
+
+// toLuaValue converts an argument value to its lua representation
+func toLuaValue(L *lua.LState, val interface{}) lua.LValue {
+	switch v := val.(type) {
+	case string:
+		return lua.LString(v)
+	case bool:
+		return lua.LBool(v)
+	case []interface{}:
+		t := L.NewTable()
+		for _, item := range v {
+			t.Append(toLuaValue(L, item))
+		}
+		return t
+	case map[string]interface{}:
+		t := L.NewTable()
+		for k, item := range v {
+			t.RawSetString(k, toLuaValue(L, item))
+		}
+		return t
+	}
+
+	return lua.LString(fmt.Sprint(val))
+}
+
+// LuaParser is a parser enabling lua code to do the parsing
+type LuaParser struct {
+	name   string
+	parser ParseFunc
+	args   ArgDef
+	script string
+}
+
+// Name returns the name of the parser
+func (lp *LuaParser) Name() string {
+	return lp.name
+}
+
+// ParseFunc returns the function used to parse the value(s)
+func (lp *LuaParser) Parser() ParseFunc {
+	return lp.parser
+}
+
+// Args returns the provided values used for the parsing
+func (lp *LuaParser) ArgDef() ArgDef {
+	return lp.args
+}
+
+// Parse runs the parser
+func (lp *LuaParser) Parse(ctx context.Context, args FuncArgs) (string, error) {
+	return lp.parser(ctx, args)
+}
+
+// Script returns the lua script used by the parser
+func (lp *LuaParser) Script() string {
+	return lp.script
+}
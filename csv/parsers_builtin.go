@@ -1,12 +1,14 @@
 package csv
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strconv"
@@ -23,6 +25,73 @@ func init() {
 		fileExistsParser,
 		fileMd5Parser,
 		containsParser,
+		redisGetParser,
+		redisSetParser,
+		execParser,
+		regexReplaceParser,
+		trimParser,
+		ltrimParser,
+		rtrimParser,
+		padLeftParser,
+		padRightParser,
+		fixedLengthParser,
+		substringParser,
+		dateFormatParser,
+		mathParser,
+		roundParser,
+		floorParser,
+		ceilParser,
+		truncateParser,
+		coalesceParser,
+		replaceParser,
+		splitIndexParser,
+		titleCaseParser,
+		sentenceCaseParser,
+		camelCaseParser,
+		snakeCaseParser,
+		slugifyParser,
+		transliterateParser,
+		md5Parser,
+		sha1Parser,
+		sha256Parser,
+		hmacParser,
+		base64EncodeParser,
+		base64DecodeParser,
+		hexEncodeParser,
+		hexDecodeParser,
+		uuidParser,
+		sequenceParser,
+		mapValueParser,
+		lookupParser,
+		jsonExtractParser,
+		currencyParseParser,
+		currencyFormatParser,
+		checkDigitParser,
+		addCheckDigitParser,
+		ipValidParser,
+		ipInCidrParser,
+		ipSubnetParser,
+		ipAnonymizeParser,
+		distanceParser,
+		templateParser,
+		ifThenParser,
+		switchParser,
+		lengthParser,
+		wordCountParser,
+		stripHtmlParser,
+		htmlEscapeParser,
+		htmlUnescapeParser,
+		urlEscapeParser,
+		urlUnescapeParser,
+		jsonEscapeParser,
+		jsonUnescapeParser,
+		envParser,
+		fileSizeParser,
+		fileMtimeParser,
+		encryptParser,
+		decryptParser,
+		maskParser,
+		fakeParser,
 	)
 
 	// This should not happen
@@ -44,7 +113,7 @@ var toUppercaseParser = &Parser{
 }
 
 func changeCase(upper bool) ParseFunc {
-	return func(args FuncArgs) (string, error) {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
 		val, ok := args["value"]
 		if !ok {
 			return "", errors.New("val argument not provided")
@@ -69,7 +138,7 @@ var concatParser = &Parser{
 	args:   ArgDef{"values": reflect.TypeOf([]interface{}{})},
 }
 
-func concat(args FuncArgs) (string, error) {
+func concat(ctx context.Context, args FuncArgs) (string, error) {
 	values, ok := args["values"]
 	if !ok {
 		return "", errors.New("values argument not provided")
@@ -98,7 +167,7 @@ var fileExtParser = &Parser{
 	args:   ArgDef{"value": reflect.TypeOf("")},
 }
 
-func extParser(args FuncArgs) (string, error) {
+func extParser(ctx context.Context, args FuncArgs) (string, error) {
 	val, ok := args["filename"]
 	if !ok {
 		return "", errors.New("filename argument not provided")
@@ -120,7 +189,7 @@ var fileExistsParser = &Parser{
 	args:   ArgDef{"value": reflect.TypeOf("")},
 }
 
-func fileExists(args FuncArgs) (string, error) {
+func fileExists(ctx context.Context, args FuncArgs) (string, error) {
 	val, ok := args["filename"]
 	if !ok {
 		return "", errors.New("filename argument not provided")
@@ -139,7 +208,7 @@ var fileMd5Parser = &Parser{
 	args:   ArgDef{"filename": reflect.TypeOf("")},
 }
 
-func fileMd5(args FuncArgs) (string, error) {
+func fileMd5(ctx context.Context, args FuncArgs) (string, error) {
 	val, ok := args["filename"]
 	if !ok {
 		return "", errors.New("filename argument not provided")
@@ -166,7 +235,7 @@ var containsParser = &Parser{
 	args:   ArgDef{"value": reflect.TypeOf(""), "term": reflect.TypeOf(""), "trueValue": reflect.TypeOf(""), "falseValue": reflect.TypeOf("")},
 }
 
-func contains(args FuncArgs) (string, error) {
+func contains(ctx context.Context, args FuncArgs) (string, error) {
 	var err error
 
 	var val string
@@ -196,3 +265,59 @@ func contains(args FuncArgs) (string, error) {
 
 	return falseVal, nil
 }
+
+var execParser = &Parser{
+	name:   "exec",
+	parser: execCommand,
+	args: ArgDef{
+		"command": reflect.TypeOf(""),
+		"args":    reflect.TypeOf([]interface{}{}),
+		"value":   reflect.TypeOf(""),
+		"values":  reflect.TypeOf([]interface{}{}),
+	},
+}
+
+// execCommand runs an external command, pipes the value (or values, one per line)
+// to its standard input and returns its standard output without the trailing new line
+func execCommand(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var command string
+	if command, err = argString(args, "command"); err != nil {
+		return "", err
+	}
+
+	var cmdArgs []string
+	if argsI, ok := args["args"]; ok {
+		for _, a := range argsI.([]interface{}) {
+			cmdArgs = append(cmdArgs, fmt.Sprint(a))
+		}
+	}
+
+	var input []string
+	if _, ok := args["value"]; ok {
+		var val string
+		if val, err = argString(args, "value"); err != nil {
+			return "", err
+		}
+		input = append(input, val)
+	}
+	if valuesI, ok := args["values"]; ok {
+		for _, v := range valuesI.([]interface{}) {
+			input = append(input, fmt.Sprint(v))
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, command, cmdArgs...)
+	cmd.Stdin = strings.NewReader(strings.Join(input, "\n"))
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "command '%s' failed: %s", command, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,190 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Check digit algorithms of the checkDigit and addCheckDigit parsers
+const (
+	CheckDigitLuhn  = "luhn"  // payment card numbers, IMEI...
+	CheckDigitMod97 = "mod97" // ISO 7064 MOD 97-10, as used by IBAN
+)
+
+// checkDigitAlgorithm returns the algorithm argument, luhn by default
+func checkDigitAlgorithm(args FuncArgs) (string, error) {
+	algorithm, err := argStringOpt(args, "algorithm", CheckDigitLuhn)
+	if err != nil {
+		return "", err
+	}
+
+	if algorithm != CheckDigitLuhn && algorithm != CheckDigitMod97 {
+		return "", fmt.Errorf("algorithm must either be '%s' or '%s'", CheckDigitLuhn, CheckDigitMod97)
+	}
+
+	return algorithm, nil
+}
+
+// checkDigitNumber removes the spaces and dashes commonly used to group the digits, eg. in card numbers and IBANs
+func checkDigitNumber(val string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, val))
+}
+
+// luhnSum returns the Luhn sum of the digits, doubling every second digit from the right starting
+// with the last one if double is true. ok is false if the number is not only made of digits
+func luhnSum(number string, double bool) (sum int, ok bool) {
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return 0, false
+		}
+
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum, true
+}
+
+// mod97 returns the remainder of the division by 97 of the number, whose letters count as
+// two digits (A is 10, B is 11...). ok is false if the number has other characters
+func mod97(number string) (rem int, ok bool) {
+	for _, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return 0, false
+		}
+	}
+
+	return rem, true
+}
+
+// validCheckDigit returns whether the check digit of the number is valid. With mod97, the
+// number is an IBAN whose check digits are the third and fourth characters
+func validCheckDigit(number string, algorithm string) bool {
+	if algorithm == CheckDigitMod97 {
+		if len(number) < 5 {
+			return false
+		}
+
+		rem, ok := mod97(number[4:] + number[:4])
+		return ok && rem == 1
+	}
+
+	if len(number) < 2 {
+		return false
+	}
+
+	sum, ok := luhnSum(number, false)
+	return ok && sum%10 == 0
+}
+
+var checkDigitParser = &Parser{
+	name:   "checkDigit",
+	parser: checkDigit,
+	args: ArgDef{
+		"value":      reflect.TypeOf(""),
+		"algorithm":  reflect.TypeOf(""),
+		"trueValue":  reflect.TypeOf(""),
+		"falseValue": reflect.TypeOf(""),
+	},
+}
+
+// checkDigit returns trueValue if the check digit of the value is valid according to the algorithm,
+// or falseValue otherwise. Spaces and dashes are ignored
+func checkDigit(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var algorithm string
+	if algorithm, err = checkDigitAlgorithm(args); err != nil {
+		return "", err
+	}
+
+	var trueVal string
+	if trueVal, err = argStringOpt(args, "trueValue", "true"); err != nil {
+		return "", err
+	}
+
+	var falseVal string
+	if falseVal, err = argStringOpt(args, "falseValue", "false"); err != nil {
+		return "", err
+	}
+
+	if validCheckDigit(checkDigitNumber(val), algorithm) {
+		return trueVal, nil
+	}
+
+	return falseVal, nil
+}
+
+var addCheckDigitParser = &Parser{
+	name:   "addCheckDigit",
+	parser: addCheckDigit,
+	args: ArgDef{
+		"value":     reflect.TypeOf(""),
+		"algorithm": reflect.TypeOf(""),
+	},
+}
+
+// addCheckDigit appends the Luhn check digit to the number. With mod97, the value is an IBAN
+// without check digits, eg. 'DE370400440532013000', and the check digits are inserted after the
+// country code. Spaces and dashes are removed
+func addCheckDigit(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var algorithm string
+	if algorithm, err = checkDigitAlgorithm(args); err != nil {
+		return "", err
+	}
+
+	number := checkDigitNumber(val)
+
+	if algorithm == CheckDigitMod97 {
+		if len(number) < 3 {
+			return "", fmt.Errorf("'%s' is not an IBAN without check digits", val)
+		}
+
+		rem, ok := mod97(number[2:] + number[:2] + "00")
+		if !ok {
+			return "", fmt.Errorf("'%s' is not an IBAN without check digits", val)
+		}
+
+		return fmt.Sprintf("%s%02d%s", number[:2], 98-rem, number[2:]), nil
+	}
+
+	sum, ok := luhnSum(number, true)
+	if !ok || number == "" {
+		return "", fmt.Errorf("'%s' is not a number", val)
+	}
+
+	return number + strconv.Itoa((10-sum%10)%10), nil
+}
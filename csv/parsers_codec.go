@@ -0,0 +1,215 @@
+package csv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"html"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Codecs of the encode and decode parsers
+const (
+	codecBase64 = "base64"
+	codecHex    = "hex"
+)
+
+var base64EncodeParser = &Parser{
+	name:   "base64Encode",
+	parser: encodeValue(codecBase64),
+	args:   ArgDef{"value": reflect.TypeOf(""), "urlSafe": reflect.TypeOf("")},
+}
+
+var base64DecodeParser = &Parser{
+	name:   "base64Decode",
+	parser: decodeValue(codecBase64),
+	args:   ArgDef{"value": reflect.TypeOf(""), "urlSafe": reflect.TypeOf(""), "onError": reflect.TypeOf("")},
+}
+
+var hexEncodeParser = &Parser{
+	name:   "hexEncode",
+	parser: encodeValue(codecHex),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var hexDecodeParser = &Parser{
+	name:   "hexDecode",
+	parser: decodeValue(codecHex),
+	args:   ArgDef{"value": reflect.TypeOf(""), "onError": reflect.TypeOf("")},
+}
+
+// base64Encoding returns the standard base64 encoding, or the URL-safe one if the urlSafe argument is true
+func base64Encoding(args FuncArgs) (*base64.Encoding, error) {
+	urlSafe, err := argBoolOpt(args, "urlSafe", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if urlSafe {
+		return base64.URLEncoding, nil
+	}
+
+	return base64.StdEncoding, nil
+}
+
+// encodeValue returns the parse function encoding the value with the codec
+func encodeValue(codec string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		if codec == codecHex {
+			return hex.EncodeToString([]byte(val)), nil
+		}
+
+		enc, err := base64Encoding(args)
+		if err != nil {
+			return "", err
+		}
+
+		return enc.EncodeToString([]byte(val)), nil
+	}
+}
+
+// decodeValue returns the parse function decoding the value with the codec. Values which
+// cannot be decoded are handled according to the onError argument
+func decodeValue(codec string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		onError, err := parseErrorPolicy(args)
+		if err != nil {
+			return "", err
+		}
+
+		var decoded []byte
+		if codec == codecHex {
+			decoded, err = hex.DecodeString(strings.TrimSpace(val))
+		} else {
+			var enc *base64.Encoding
+			if enc, err = base64Encoding(args); err != nil {
+				return "", err
+			}
+			decoded, err = enc.DecodeString(strings.TrimSpace(val))
+		}
+
+		if err != nil {
+			return onParseError(onError, val, errors.Wrapf(err, "invalid %s value '%s'", codec, val))
+		}
+
+		return string(decoded), nil
+	}
+}
+
+// Syntaxes of the escape and unescape parsers
+const (
+	escapeHTML = "html"
+	escapeURL  = "url"
+	escapeJSON = "json"
+)
+
+var htmlEscapeParser = &Parser{
+	name:   "htmlEscape",
+	parser: escapeValue(escapeHTML),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var htmlUnescapeParser = &Parser{
+	name:   "htmlUnescape",
+	parser: unescapeValue(escapeHTML),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var urlEscapeParser = &Parser{
+	name:   "urlEscape",
+	parser: escapeValue(escapeURL),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var urlUnescapeParser = &Parser{
+	name:   "urlUnescape",
+	parser: unescapeValue(escapeURL),
+	args:   ArgDef{"value": reflect.TypeOf(""), "onError": reflect.TypeOf("")},
+}
+
+var jsonEscapeParser = &Parser{
+	name:   "jsonEscape",
+	parser: escapeValue(escapeJSON),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var jsonUnescapeParser = &Parser{
+	name:   "jsonUnescape",
+	parser: unescapeValue(escapeJSON),
+	args:   ArgDef{"value": reflect.TypeOf(""), "onError": reflect.TypeOf("")},
+}
+
+// escapeValue returns the parse function escaping the value for the syntax: HTML entities, URL query
+// component, or the content of a JSON string without the surrounding quotes
+func escapeValue(syntax string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		switch syntax {
+		case escapeHTML:
+			return html.EscapeString(val), nil
+		case escapeURL:
+			return url.QueryEscape(val), nil
+		}
+
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(val); err != nil {
+			return "", err
+		}
+
+		return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSuffix(b.String(), "\n"), `"`), `"`), nil
+	}
+}
+
+// unescapeValue returns the parse function reversing escapeValue for the syntax. Values which
+// cannot be unescaped are handled according to the onError argument
+func unescapeValue(syntax string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		if syntax == escapeHTML {
+			return html.UnescapeString(val), nil
+		}
+
+		onError, err := parseErrorPolicy(args)
+		if err != nil {
+			return "", err
+		}
+
+		var out string
+		if syntax == escapeURL {
+			out, err = url.QueryUnescape(val)
+		} else {
+			err = json.Unmarshal([]byte(`"`+val+`"`), &out)
+		}
+
+		if err != nil {
+			return onParseError(onError, val, errors.Wrapf(err, "could not unescape '%s'", val))
+		}
+
+		return out, nil
+	}
+}
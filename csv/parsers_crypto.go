@@ -0,0 +1,129 @@
+package csv
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"os"
+	"reflect"
+)
+
+var encryptParser = &Parser{
+	name:   "encrypt",
+	parser: encrypt,
+	args:   ArgDef{"value": reflect.TypeOf(""), "keyEnv": reflect.TypeOf(""), "keyEncoding": reflect.TypeOf("")},
+}
+
+var decryptParser = &Parser{
+	name:   "decrypt",
+	parser: decrypt,
+	args:   ArgDef{"value": reflect.TypeOf(""), "keyEnv": reflect.TypeOf(""), "keyEncoding": reflect.TypeOf(""), "onError": reflect.TypeOf("")},
+}
+
+// aesGCM returns the AES-GCM cipher keyed with the value of the keyEnv environment variable, which is
+// a 16, 24 or 32 bytes key, for AES-128, AES-192 or AES-256, encoded as keyEncoding, hexadecimal by default
+func aesGCM(args FuncArgs) (cipher.AEAD, error) {
+	var err error
+
+	var keyEnv string
+	if keyEnv, err = argString(args, "keyEnv"); err != nil {
+		return nil, err
+	}
+
+	var keyEncoding string
+	if keyEncoding, err = argStringOpt(args, "keyEncoding", codecHex); err != nil {
+		return nil, err
+	}
+
+	encoded, ok := os.LookupEnv(keyEnv)
+	if !ok || encoded == "" {
+		return nil, fmt.Errorf("environment variable '%s' is not set", keyEnv)
+	}
+
+	var key []byte
+	switch keyEncoding {
+	case codecHex:
+		key, err = hex.DecodeString(encoded)
+	case codecBase64:
+		key, err = base64.StdEncoding.DecodeString(encoded)
+	default:
+		return nil, fmt.Errorf("keyEncoding must either be '%s' or '%s'", codecHex, codecBase64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the key of '%s' must be encoded as %s", keyEnv, keyEncoding)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid key in '%s'", keyEnv)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt encrypts the value with AES-GCM and returns the random nonce followed by the ciphertext,
+// encoded as base64. Empty values are kept empty
+func encrypt(ctx context.Context, args FuncArgs) (string, error) {
+	val, err := argString(args, "value")
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := aesGCM(args)
+	if err != nil {
+		return "", err
+	}
+
+	if val == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(val), nil)), nil
+}
+
+// decrypt decrypts the value encrypted by the encrypt parser with the same key. Values which cannot
+// be decrypted, eg. altered or encrypted with another key, are handled according to onError
+func decrypt(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	gcm, err := aesGCM(args)
+	if err != nil {
+		return "", err
+	}
+
+	if val == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(val)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return onParseError(onError, val, fmt.Errorf("'%s' is not an encrypted value", val))
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return onParseError(onError, val, errors.Wrapf(err, "could not decrypt '%s'", val))
+	}
+
+	return string(plain), nil
+}
@@ -0,0 +1,210 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Ways the currency is written by the currencyFormat parser
+const (
+	CurrencySymbol = "symbol" // eg. '€ 1.234,50'
+	CurrencyCode   = "code"   // eg. 'EUR 1.234,50'
+	CurrencyNone   = "none"   // eg. '1.234,50'
+)
+
+// defaultLocale is the locale of the currency parsers when none is provided
+const defaultLocale = "en-US"
+
+// parseLocale returns the language tag of the locale argument
+func parseLocale(args FuncArgs) (language.Tag, error) {
+	locale, err := argStringOpt(args, "locale", defaultLocale)
+	if err != nil {
+		return language.Tag{}, err
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return language.Tag{}, errors.Wrapf(err, "invalid locale '%s'", locale)
+	}
+
+	return tag, nil
+}
+
+// decimalSeparator returns the decimal separator of the locale, eg. ',' for 'fr-FR'
+func decimalSeparator(tag language.Tag) rune {
+	formatted := []rune(message.NewPrinter(tag).Sprint(number.Decimal(1.5, number.Scale(1))))
+	if len(formatted) < 3 {
+		return '.'
+	}
+
+	return formatted[1]
+}
+
+var currencyParseParser = &Parser{
+	name:   "currencyParse",
+	parser: currencyParse,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"locale":  reflect.TypeOf(""),
+		"onError": reflect.TypeOf(""),
+	},
+}
+
+// currencyParse strips the currency symbols, codes and grouping separators from the amount written
+// according to the locale, eg. '-1.234,50 €' becomes '-1234.50' for 'de-DE'. Amounts in parentheses
+// are negative. Values without digits are handled according to onError
+func currencyParse(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	tag, err := parseLocale(args)
+	if err != nil {
+		return "", err
+	}
+
+	sep := decimalSeparator(tag)
+	trimmed := strings.TrimSpace(val)
+
+	var out strings.Builder
+	var hasDigits, hasSep bool
+	negative := strings.ContainsAny(trimmed, "-−") || (strings.HasPrefix(trimmed, "(") && strings.HasSuffix(trimmed, ")"))
+
+	for _, r := range trimmed {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigits = true
+			out.WriteRune(r)
+		case r == sep && !hasSep:
+			hasSep = true
+			out.WriteByte('.')
+		}
+	}
+
+	if !hasDigits {
+		return onParseError(onError, val, fmt.Errorf("'%s' is not an amount", val))
+	}
+
+	amount := strings.TrimSuffix(out.String(), ".")
+	if strings.HasPrefix(amount, ".") {
+		amount = "0" + amount
+	}
+
+	if negative {
+		amount = "-" + amount
+	}
+
+	return amount, nil
+}
+
+var currencyFormatParser = &Parser{
+	name:   "currencyFormat",
+	parser: currencyFormat,
+	args: ArgDef{
+		"value":     reflect.TypeOf(""),
+		"locale":    reflect.TypeOf(""),
+		"currency":  reflect.TypeOf(""),
+		"symbol":    reflect.TypeOf(""),
+		"precision": reflect.TypeOf(""),
+		"onError":   reflect.TypeOf(""),
+	},
+}
+
+// currencyFormat formats the number as an amount of the currency, eg. '1234.5' becomes '€ 1.234,50' for
+// the 'de-DE' locale. The currency is the one of the locale's region unless an ISO code is provided, and
+// the precision is the one of the currency unless provided. Values which are not numbers are handled
+// according to onError
+func currencyFormat(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	tag, err := parseLocale(args)
+	if err != nil {
+		return "", err
+	}
+
+	var code string
+	if code, err = argStringOpt(args, "currency", ""); err != nil {
+		return "", err
+	}
+
+	var unit currency.Unit
+	if code == "" {
+		var conf language.Confidence
+		if unit, conf = currency.FromTag(tag); conf == language.No {
+			return "", fmt.Errorf("no currency found for locale '%s', the currency must be provided", tag)
+		}
+	} else if unit, err = currency.ParseISO(code); err != nil {
+		return "", errors.Wrapf(err, "invalid currency '%s'", code)
+	}
+
+	var symbol string
+	if symbol, err = argStringOpt(args, "symbol", CurrencySymbol); err != nil {
+		return "", err
+	}
+
+	if symbol != CurrencySymbol && symbol != CurrencyCode && symbol != CurrencyNone {
+		return "", fmt.Errorf("symbol must either be '%s', '%s' or '%s'", CurrencySymbol, CurrencyCode, CurrencyNone)
+	}
+
+	scale, _ := currency.Standard.Rounding(unit)
+
+	var precision int
+	if precision, err = argIntOpt(args, "precision", scale); err != nil {
+		return "", err
+	}
+
+	if precision < 0 {
+		return "", errors.New("precision cannot be negative")
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		return onParseError(onError, val, fmt.Errorf("'%s' is not a number", val))
+	}
+
+	// the sign comes before the currency, eg. '-€ 1.234,50'
+	var sign string
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	p := message.NewPrinter(tag)
+	formatted := p.Sprint(number.Decimal(amount, number.Scale(precision)))
+
+	switch symbol {
+	case CurrencySymbol:
+		return sign + p.Sprint(currency.Symbol(unit)) + " " + formatted, nil
+	case CurrencyCode:
+		return sign + unit.String() + " " + formatted, nil
+	}
+
+	return sign + formatted, nil
+}
@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Behaviours of the parsers failing to convert a value, besides aborting with OnErrorAbort
+const (
+	OnErrorEmpty = "empty" // the value becomes empty
+	OnErrorKeep  = "keep"  // the value is kept unchanged
+)
+
+// parseErrorPolicy returns the onError argument of the parser, OnErrorAbort by default
+func parseErrorPolicy(args FuncArgs) (string, error) {
+	onError, err := argStringOpt(args, "onError", OnErrorAbort)
+	if err != nil {
+		return "", err
+	}
+
+	if onError != OnErrorAbort && onError != OnErrorEmpty && onError != OnErrorKeep {
+		return "", fmt.Errorf("onError must either be '%s', '%s' or '%s'", OnErrorAbort, OnErrorEmpty, OnErrorKeep)
+	}
+
+	return onError, nil
+}
+
+// onParseError applies the error policy to the value which failed to convert
+func onParseError(onError string, val string, err error) (string, error) {
+	switch onError {
+	case OnErrorEmpty:
+		return "", nil
+	case OnErrorKeep:
+		return val, nil
+	}
+
+	return "", err
+}
+
+var dateFormatParser = &Parser{
+	name:   "dateFormat",
+	parser: dateFormat,
+	args: ArgDef{
+		"value":       reflect.TypeOf(""),
+		"from":        reflect.TypeOf(""),
+		"fromLayouts": reflect.TypeOf([]interface{}{}),
+		"to":          reflect.TypeOf(""),
+		"onError":     reflect.TypeOf(""),
+	},
+}
+
+// dateFormat parses the date from the first of the layouts matching the value and formats it with the
+// to layout. Without from layouts, the common layouts are tried. Empty values are kept empty
+func dateFormat(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var to string
+	if to, err = argString(args, "to"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	var layouts []string
+	if _, ok := args["from"]; ok {
+		var from string
+		if from, err = argString(args, "from"); err != nil {
+			return "", err
+		}
+		layouts = append(layouts, from)
+	}
+	if fromLayouts, ok := args["fromLayouts"]; ok {
+		for _, layout := range fromLayouts.([]interface{}) {
+			layouts = append(layouts, fmt.Sprint(layout))
+		}
+	}
+	if len(layouts) == 0 {
+		layouts = timeLayouts
+	}
+
+	if val == "" {
+		return "", nil
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t.Format(to), nil
+		}
+	}
+
+	return onParseError(onError, val, fmt.Errorf("'%s' does not match any of the layouts", val))
+}
@@ -0,0 +1,170 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"hash/fnv"
+	"math/rand/v2"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of values generated by the fake parser
+const (
+	FakeFirstName = "firstName"
+	FakeLastName  = "lastName"
+	FakeName      = "name"
+	FakeEmail     = "email"
+	FakeAddress   = "address"
+	FakeCity      = "city"
+	FakeCompany   = "company"
+	FakeDate      = "date"
+)
+
+var (
+	fakeFirstNames = []string{
+		"Alice", "Amir", "Anna", "Ben", "Camille", "Carlos", "Chloe", "Daniel", "Elena", "Emma", "Fatima", "Grace",
+		"Hugo", "Ines", "Jack", "James", "Julia", "Kenji", "Laura", "Leo", "Lucas", "Maria", "Mia", "Noah", "Olivia",
+		"Omar", "Paul", "Priya", "Sofia", "Thomas", "Yuki", "Zoe",
+	}
+	fakeLastNames = []string{
+		"Anderson", "Bernard", "Brown", "Chen", "Costa", "Davies", "Dubois", "Evans", "Garcia", "Hansen", "Ivanova",
+		"Jones", "Kim", "Kowalski", "Lee", "Martin", "Meyer", "Moreau", "Nguyen", "Novak", "Patel", "Rossi", "Santos",
+		"Schmidt", "Silva", "Smith", "Suzuki", "Taylor", "Walker", "Williams", "Wilson", "Young",
+	}
+	fakeStreets = []string{
+		"Acacia", "Bridge", "Cedar", "Church", "Elm", "High", "Hill", "Lake", "Maple", "Mill", "Oak", "Park",
+		"Pine", "River", "Station", "Victoria", "Willow", "York",
+	}
+	fakeStreetTypes = []string{"Street", "Road", "Avenue", "Lane", "Drive", "Way", "Place", "Boulevard"}
+	fakeCities      = []string{
+		"Springfield", "Riverton", "Lakewood", "Fairview", "Greenville", "Kingston", "Bristol", "Clayton",
+		"Franklin", "Georgetown", "Madison", "Milton", "Newport", "Oakland", "Salem", "Winchester",
+	}
+	fakeCompanyWords = []string{
+		"Apex", "Blue", "Bright", "Cedar", "Core", "Delta", "Everest", "Falcon", "Global", "Harbor", "Iron",
+		"Lumen", "Nova", "Orbit", "Pioneer", "Quantum", "Summit", "Vertex",
+	}
+	fakeCompanyTypes    = []string{"Industries", "Labs", "Systems", "Logistics", "Consulting", "Foods", "Energy", "Media"}
+	fakeCompanySuffixes = []string{"Inc.", "Ltd", "LLC", "Group", "& Co."}
+	// fakeEmailDomains are reserved for documentation, so that the generated addresses never reach anyone
+	fakeEmailDomains = []string{"example.com", "example.org", "example.net"}
+)
+
+var fakeParser = &Parser{
+	name:   "fake",
+	parser: fake,
+	args: ArgDef{
+		"kind":   reflect.TypeOf(""),
+		"seed":   reflect.TypeOf(""),
+		"key":    reflect.TypeOf(""),
+		"min":    reflect.TypeOf(""),
+		"max":    reflect.TypeOf(""),
+		"layout": reflect.TypeOf(""),
+	},
+}
+
+// fake generates a realistic value of the kind, eg. a name, an email address or a date between min and max,
+// to build test fixtures. Values are random unless a seed is provided, in which case each run generates the
+// same values for the same rows, derived from their position like sequence. Values can also be derived from a key, eg. an identifier column, so that the
+// same key always gets the same value, whatever the order the rows are parsed in
+func fake(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var kind string
+	if kind, err = argString(args, "kind"); err != nil {
+		return "", err
+	}
+
+	var seed string
+	if seed, err = argStringOpt(args, "seed", ""); err != nil {
+		return "", err
+	}
+
+	var key string
+	if key, err = argStringOpt(args, "key", ""); err != nil {
+		return "", err
+	}
+
+	var r *rand.Rand
+	switch {
+	case key != "":
+		r = fakeRand(kind, seed, key)
+	case seed != "":
+		r = fakeRand(kind, seed, strconv.Itoa(nextSequence(ctx, "fake/"+kind+"/"+seed, 0, 1)))
+	default:
+		r = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	pick := func(list []string) string { return list[r.IntN(len(list))] }
+
+	switch kind {
+	case FakeFirstName:
+		return pick(fakeFirstNames), nil
+	case FakeLastName:
+		return pick(fakeLastNames), nil
+	case FakeName:
+		return pick(fakeFirstNames) + " " + pick(fakeLastNames), nil
+	case FakeEmail:
+		local := strings.ToLower(pick(fakeFirstNames) + "." + pick(fakeLastNames))
+		return fmt.Sprintf("%s%d@%s", local, r.IntN(100), pick(fakeEmailDomains)), nil
+	case FakeAddress:
+		return fmt.Sprintf("%d %s %s, %s", 1+r.IntN(250), pick(fakeStreets), pick(fakeStreetTypes), pick(fakeCities)), nil
+	case FakeCity:
+		return pick(fakeCities), nil
+	case FakeCompany:
+		return pick(fakeCompanyWords) + " " + pick(fakeCompanyTypes) + " " + pick(fakeCompanySuffixes), nil
+	case FakeDate:
+		return fakeDate(r, args)
+	}
+
+	return "", fmt.Errorf("kind must be one of '%s', '%s', '%s', '%s', '%s', '%s', '%s' or '%s', not '%s'",
+		FakeFirstName, FakeLastName, FakeName, FakeEmail, FakeAddress, FakeCity, FakeCompany, FakeDate, kind)
+}
+
+// fakeRand returns the random generator of the value of the kind, derived from the seed and the key
+func fakeRand(kind string, seed string, key string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(kind + "\x00" + seed + "\x00" + key))
+
+	return rand.New(rand.NewPCG(h.Sum64(), 0))
+}
+
+// fakeDate returns a date between the min and max arguments, formatted as 'YYYY-MM-DD' and defaulting to
+// 1970-01-01 and today, formatted with the layout
+func fakeDate(r *rand.Rand, args FuncArgs) (string, error) {
+	var err error
+
+	var minStr, maxStr string
+	if minStr, err = argStringOpt(args, "min", "1970-01-01"); err != nil {
+		return "", err
+	}
+	if maxStr, err = argStringOpt(args, "max", time.Now().Format(time.DateOnly)); err != nil {
+		return "", err
+	}
+
+	var layout string
+	if layout, err = argStringOpt(args, "layout", time.DateOnly); err != nil {
+		return "", err
+	}
+
+	minDate, err := time.Parse(time.DateOnly, minStr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid min date '%s'", minStr)
+	}
+
+	maxDate, err := time.Parse(time.DateOnly, maxStr)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid max date '%s'", maxStr)
+	}
+
+	if maxDate.Before(minDate) {
+		return "", fmt.Errorf("max date '%s' is before min date '%s'", maxStr, minStr)
+	}
+
+	days := int(maxDate.Sub(minDate).Hours()/24) + 1
+	return minDate.AddDate(0, 0, r.IntN(days)).Format(layout), nil
+}
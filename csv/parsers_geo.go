@@ -0,0 +1,105 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Units of the distance parser
+const (
+	DistanceKm    = "km"
+	DistanceMiles = "mi"
+)
+
+// earthRadiusKm is the mean radius of the Earth used by the haversine formula
+const earthRadiusKm = 6371.0088
+
+// kmPerMile is the number of kilometres in a mile
+const kmPerMile = 1.609344
+
+// argCoordinate returns the latitude or longitude argument in degrees, which must be within the limit
+func argCoordinate(args FuncArgs, argName string, limit float64) (float64, error) {
+	vStr, err := argString(args, argName)
+	if err != nil {
+		return 0, err
+	}
+
+	deg, err := strconv.ParseFloat(strings.TrimSpace(vStr), 64)
+	if err != nil || math.Abs(deg) > limit {
+		return 0, fmt.Errorf("'%s' is not a valid %s", vStr, argName[:3])
+	}
+
+	return deg, nil
+}
+
+// haversine returns the great-circle distance in kilometres between the two points given in degrees
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+var distanceParser = &Parser{
+	name:   "distance",
+	parser: distance,
+	args: ArgDef{
+		"lat1":      reflect.TypeOf(""),
+		"lon1":      reflect.TypeOf(""),
+		"lat2":      reflect.TypeOf(""),
+		"lon2":      reflect.TypeOf(""),
+		"unit":      reflect.TypeOf(""),
+		"precision": reflect.TypeOf(""),
+	},
+}
+
+// distance returns the haversine distance between the two points in kilometres or miles, rounded
+// to the precision if provided. Each coordinate is either a column or a fixed value, and the
+// distance is empty if any of them is empty
+func distance(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var unit string
+	if unit, err = argStringOpt(args, "unit", DistanceKm); err != nil {
+		return "", err
+	}
+
+	if unit != DistanceKm && unit != DistanceMiles {
+		return "", fmt.Errorf("unit must either be '%s' or '%s'", DistanceKm, DistanceMiles)
+	}
+
+	var precision int
+	if precision, err = argIntOpt(args, "precision", -1); err != nil {
+		return "", err
+	}
+
+	var coords [4]float64
+	for i, arg := range []string{"lat1", "lon1", "lat2", "lon2"} {
+		if vStr, _ := args[arg].(string); strings.TrimSpace(vStr) == "" {
+			return "", nil
+		}
+
+		limit := 90.0
+		if strings.HasPrefix(arg, "lon") {
+			limit = 180
+		}
+
+		if coords[i], err = argCoordinate(args, arg, limit); err != nil {
+			return "", err
+		}
+	}
+
+	dist := haversine(coords[0], coords[1], coords[2], coords[3])
+	if unit == DistanceMiles {
+		dist /= kmPerMile
+	}
+
+	return strconv.FormatFloat(dist, 'f', precision, 64), nil
+}
@@ -0,0 +1,172 @@
+package csv
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"hash"
+	"os"
+	"reflect"
+)
+
+// hashFuncs are the hash algorithms supported by the hash parsers, mapped by name
+var hashFuncs = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+var md5Parser = &Parser{
+	name:   "md5",
+	parser: hashValue("md5"),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var sha1Parser = &Parser{
+	name:   "sha1",
+	parser: hashValue("sha1"),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var sha256Parser = &Parser{
+	name:   "sha256",
+	parser: hashValue("sha256"),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+// hashValue returns the parse function hashing the value with the algorithm, as hexadecimal
+func hashValue(algorithm string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		h := hashFuncs[algorithm]()
+		h.Write([]byte(val))
+
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+}
+
+var hmacParser = &Parser{
+	name:   "hmac",
+	parser: hmacValue,
+	args: ArgDef{
+		"value":     reflect.TypeOf(""),
+		"key":       reflect.TypeOf(""),
+		"keyEnv":    reflect.TypeOf(""),
+		"algorithm": reflect.TypeOf(""),
+	},
+}
+
+// hmacValue returns the HMAC of the value as hexadecimal, keyed with the key or with the value of the
+// keyEnv environment variable, so that values are pseudonymized deterministically
+func hmacValue(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var key string
+	if key, err = argStringOpt(args, "key", ""); err != nil {
+		return "", err
+	}
+
+	var keyEnv string
+	if keyEnv, err = argStringOpt(args, "keyEnv", ""); err != nil {
+		return "", err
+	}
+
+	var algorithm string
+	if algorithm, err = argStringOpt(args, "algorithm", "sha256"); err != nil {
+		return "", err
+	}
+
+	if (key == "") == (keyEnv == "") {
+		return "", errors.New("either the key or the keyEnv argument must be provided")
+	}
+
+	if keyEnv != "" {
+		var ok bool
+		if key, ok = os.LookupEnv(keyEnv); !ok || key == "" {
+			return "", fmt.Errorf("environment variable '%s' is not set", keyEnv)
+		}
+	}
+
+	newHash, ok := hashFuncs[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm '%s', must be one of 'md5', 'sha1', 'sha256' or 'sha512'", algorithm)
+	}
+
+	mac := hmac.New(newHash, []byte(key))
+	mac.Write([]byte(val))
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// uuidNamespaces are the predefined namespaces of the uuid parser, mapped by name
+var uuidNamespaces = map[string]uuid.UUID{
+	"dns":  uuid.NameSpaceDNS,
+	"url":  uuid.NameSpaceURL,
+	"oid":  uuid.NameSpaceOID,
+	"x500": uuid.NameSpaceX500,
+}
+
+var uuidParser = &Parser{
+	name:   "uuid",
+	parser: uuidValue,
+	args: ArgDef{
+		"version":   reflect.TypeOf(""),
+		"namespace": reflect.TypeOf(""),
+		"value":     reflect.TypeOf(""),
+	},
+}
+
+// uuidValue generates a random version 4 UUID, or a version 5 UUID derived from the namespace and the
+// value, which is the same for the same value. The namespace is either 'dns', 'url', 'oid', 'x500' or a UUID
+func uuidValue(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var version string
+	if version, err = argStringOpt(args, "version", "4"); err != nil {
+		return "", err
+	}
+
+	switch version {
+	case "4":
+		return uuid.NewString(), nil
+	case "5":
+	default:
+		return "", fmt.Errorf("version must either be '4' or '5', got '%s'", version)
+	}
+
+	var namespace string
+	if namespace, err = argString(args, "namespace"); err != nil {
+		return "", err
+	}
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	ns, ok := uuidNamespaces[namespace]
+	if !ok {
+		if ns, err = uuid.Parse(namespace); err != nil {
+			return "", errors.Wrapf(err, "namespace '%s' is neither a predefined namespace nor a UUID", namespace)
+		}
+	}
+
+	return uuid.NewSHA1(ns, []byte(val)).String(), nil
+}
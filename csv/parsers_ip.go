@@ -0,0 +1,219 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+// parseIP parses the IPv4 or IPv6 address, IPv4-mapped IPv6 addresses being converted to IPv4
+// and the zone of IPv6 addresses being dropped
+func parseIP(val string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(val))
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("'%s' is not an IP address", val)
+	}
+
+	return addr.Unmap().WithZone(""), nil
+}
+
+var ipValidParser = &Parser{
+	name:   "ipValid",
+	parser: ipValid,
+	args: ArgDef{
+		"value":      reflect.TypeOf(""),
+		"version":    reflect.TypeOf(""),
+		"trueValue":  reflect.TypeOf(""),
+		"falseValue": reflect.TypeOf(""),
+	},
+}
+
+// ipValid returns trueValue if the value is an IP address, of the given version (4 or 6) if provided,
+// or falseValue otherwise
+func ipValid(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var version int
+	if version, err = argIntOpt(args, "version", 0); err != nil {
+		return "", err
+	}
+
+	if version != 0 && version != 4 && version != 6 {
+		return "", errors.New("version must either be 4 or 6")
+	}
+
+	var trueVal string
+	if trueVal, err = argStringOpt(args, "trueValue", "true"); err != nil {
+		return "", err
+	}
+
+	var falseVal string
+	if falseVal, err = argStringOpt(args, "falseValue", "false"); err != nil {
+		return "", err
+	}
+
+	addr, err := parseIP(val)
+	if err != nil || (version == 4 && !addr.Is4()) || (version == 6 && !addr.Is6()) {
+		return falseVal, nil
+	}
+
+	return trueVal, nil
+}
+
+var ipInCidrParser = &Parser{
+	name:   "ipInCidr",
+	parser: ipInCidr,
+	args: ArgDef{
+		"value":      reflect.TypeOf(""),
+		"cidrs":      reflect.TypeOf([]interface{}{}),
+		"trueValue":  reflect.TypeOf(""),
+		"falseValue": reflect.TypeOf(""),
+	},
+}
+
+// ipInCidr returns trueValue if the IP address belongs to one of the networks, eg. '10.0.0.0/8',
+// or falseValue otherwise. Values which are not IP addresses are not in any network
+func ipInCidr(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	cidrs, ok := args["cidrs"].([]interface{})
+	if !ok {
+		return "", errors.New("cidrs argument not provided")
+	}
+
+	var trueVal string
+	if trueVal, err = argStringOpt(args, "trueValue", "true"); err != nil {
+		return "", err
+	}
+
+	var falseVal string
+	if falseVal, err = argStringOpt(args, "falseValue", "false"); err != nil {
+		return "", err
+	}
+
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(fmt.Sprint(cidr)))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid CIDR '%v'", cidr)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	addr, err := parseIP(val)
+	if err != nil {
+		return falseVal, nil
+	}
+
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return trueVal, nil
+		}
+	}
+
+	return falseVal, nil
+}
+
+var ipSubnetParser = &Parser{
+	name:   "ipSubnet",
+	parser: ipSubnet,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"bits":    reflect.TypeOf(""),
+		"onError": reflect.TypeOf(""),
+	},
+}
+
+// ipSubnet returns the network the IP address belongs to, eg. '192.168.1.0/24' for '192.168.1.42'.
+// The network is a /24 for IPv4 and a /48 for IPv6 unless the number of bits is provided.
+// Values which are not IP addresses are handled according to onError
+func ipSubnet(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	addr, err := parseIP(val)
+	if err != nil {
+		return onParseError(onError, val, err)
+	}
+
+	def := 24
+	if addr.Is6() {
+		def = 48
+	}
+
+	var bits int
+	if bits, err = argIntOpt(args, "bits", def); err != nil {
+		return "", err
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid number of bits %d for '%s'", bits, val)
+	}
+
+	return prefix.String(), nil
+}
+
+var ipAnonymizeParser = &Parser{
+	name:   "ipAnonymize",
+	parser: ipAnonymize,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"onError": reflect.TypeOf(""),
+	},
+}
+
+// ipAnonymize zeroes the last octet of IPv4 addresses, eg. '192.168.1.42' becomes '192.168.1.0', and the
+// last 80 bits of IPv6 addresses. Values which are not IP addresses are handled according to onError
+func ipAnonymize(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	addr, err := parseIP(val)
+	if err != nil {
+		return onParseError(onError, val, err)
+	}
+
+	bits := 24
+	if addr.Is6() {
+		bits = 48
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix.Addr().String(), nil
+}
@@ -0,0 +1,123 @@
+package csv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var jsonExtractParser = &Parser{
+	name:   "jsonExtract",
+	parser: jsonExtract,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"path":    reflect.TypeOf(""),
+		"default": reflect.TypeOf(""),
+		"onError": reflect.TypeOf(""),
+	},
+}
+
+// jsonExtract decodes the value as JSON and returns the field at the path, eg. 'customer.address.city'
+// or '$.items[0].sku'. Arrays and objects are returned as JSON, and the default value, empty if not
+// provided, is returned when the path does not exist. Values which are not valid JSON are handled
+// according to onError
+func jsonExtract(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var path string
+	if path, err = argString(args, "path"); err != nil {
+		return "", err
+	}
+
+	var def string
+	if def, err = argStringOpt(args, "default", ""); err != nil {
+		return "", err
+	}
+
+	var onError string
+	if onError, err = parseErrorPolicy(args); err != nil {
+		return "", err
+	}
+
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(val) == "" {
+		return def, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(val))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err = dec.Decode(&doc); err != nil {
+		return onParseError(onError, val, errors.Wrapf(err, "invalid JSON value '%s'", val))
+	}
+
+	for _, segment := range segments {
+		var ok bool
+		if doc, ok = jsonField(doc, segment); !ok {
+			return def, nil
+		}
+	}
+
+	return jsonValueString(doc), nil
+}
+
+// parseJSONPath splits the path into the names of the fields and the indexes of the arrays
+// it goes through. The path may start with '$', and indexes are either in brackets or between dots
+func parseJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	path = strings.TrimPrefix(path, ".")
+
+	if path == "" {
+		return nil, nil
+	}
+
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		segment = strings.Trim(segment, `'"`)
+		if segment == "" {
+			return nil, fmt.Errorf("invalid path '%s'", path)
+		}
+		segments[i] = segment
+	}
+
+	return segments, nil
+}
+
+// jsonField returns the field of the object, or the element of the array, named by the segment of the path
+func jsonField(doc interface{}, segment string) (interface{}, bool) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		field, ok := v[segment]
+		return field, ok
+	case []interface{}:
+		i, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, false
+		}
+		// negative indexes count from the end of the array
+		if i < 0 {
+			i += len(v)
+		}
+		if i < 0 || i >= len(v) {
+			return nil, false
+		}
+		return v[i], true
+	}
+
+	return nil, false
+}
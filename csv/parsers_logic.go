@@ -0,0 +1,335 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var coalesceParser = &Parser{
+	name:   "coalesce",
+	parser: coalesce,
+	args:   ArgDef{"values": reflect.TypeOf([]interface{}{})},
+}
+
+// coalesce returns the first of the values which is not empty nor blank, or an empty value if they all are
+func coalesce(ctx context.Context, args FuncArgs) (string, error) {
+	values, ok := args["values"]
+	if !ok {
+		return "", errors.New("values argument not provided")
+	}
+
+	for _, val := range values.([]interface{}) {
+		if vStr := fmt.Sprint(val); strings.TrimSpace(vStr) != "" {
+			return vStr, nil
+		}
+	}
+
+	return "", nil
+}
+
+// sequencesKey is the context key of the counters of the sequence parser
+type sequencesKey struct{}
+
+// sequences holds the next value of each sequence of a run, mapped by name, as well as the
+// number of records read by the inputs of the run
+type sequences struct {
+	sync.Mutex
+	next    map[string]int
+	records int
+}
+
+// rowPositionKey is the context key of the position of the row parsed in the run
+type rowPositionKey struct{}
+
+// rowsOffsetKey is the context key of the number of records read by the previous inputs of the run
+type rowsOffsetKey struct{}
+
+// globalSequences holds the sequences when the parser is run outside of a run
+var globalSequences = &sequences{next: map[string]int{}}
+
+// withSequences returns a context holding new sequences, so that each run starts its sequences over
+func withSequences(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sequencesKey{}, &sequences{next: map[string]int{}})
+}
+
+// withInput returns the context of the parsers run on the records of an input, the rows being
+// positioned after the records of the previous inputs. done must be called with the number of
+// records of the input once read
+func withInput(ctx context.Context) (context.Context, func(records int)) {
+	seqs, ok := ctx.Value(sequencesKey{}).(*sequences)
+	if !ok {
+		return ctx, func(int) {}
+	}
+
+	seqs.Lock()
+	defer seqs.Unlock()
+
+	done := func(records int) {
+		seqs.Lock()
+		defer seqs.Unlock()
+		seqs.records += records
+	}
+
+	return context.WithValue(ctx, rowsOffsetKey{}, seqs.records), done
+}
+
+// withRowPosition returns the context of the parsers run on the row at rowIndex in its input,
+// starting at 1, giving them its position in the run, starting at 0
+func withRowPosition(ctx context.Context, rowIndex int) context.Context {
+	offset, ok := ctx.Value(rowsOffsetKey{}).(int)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, rowPositionKey{}, offset+rowIndex-1)
+}
+
+var sequenceParser = &Parser{
+	name:   "sequence",
+	parser: sequence,
+	args:   ArgDef{"start": reflect.TypeOf(""), "step": reflect.TypeOf(""), "name": reflect.TypeOf("")},
+}
+
+// sequence returns an integer incremented by step for each row, from start. When reading the input, the
+// value is derived from the position of the row so that it does not depend on the parallelism, rejected
+// rows leaving a gap. Otherwise, eg. in operations, columns using their own sequence must give it a distinct name
+func sequence(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var start int
+	if start, err = argIntOpt(args, "start", 1); err != nil {
+		return "", err
+	}
+
+	var step int
+	if step, err = argIntOpt(args, "step", 1); err != nil {
+		return "", err
+	}
+
+	var name string
+	if name, err = argStringOpt(args, "name", ""); err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(nextSequence(ctx, name, start, step)), nil
+}
+
+// nextSequence returns the value of the named sequence for the row being parsed, derived from its
+// position in the run if known. Otherwise it returns the next value of the sequence, and increments it by step
+func nextSequence(ctx context.Context, name string, start int, step int) int {
+	if pos, ok := ctx.Value(rowPositionKey{}).(int); ok {
+		return start + pos*step
+	}
+
+	seqs, ok := ctx.Value(sequencesKey{}).(*sequences)
+	if !ok {
+		seqs = globalSequences
+	}
+
+	seqs.Lock()
+	defer seqs.Unlock()
+
+	val, ok := seqs.next[name]
+	if !ok {
+		val = start
+	}
+	seqs.next[name] = val + step
+
+	return val
+}
+
+var mapValueParser = &Parser{
+	name:   "mapValue",
+	parser: mapValue,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"mapping": reflect.TypeOf(map[string]interface{}{}),
+		"default": reflect.TypeOf(""),
+	},
+}
+
+// mapValue translates the value with the mapping. Values missing from the mapping are replaced by
+// the default value if provided, or kept unchanged otherwise
+func mapValue(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	mapping, ok := args["mapping"].(map[string]interface{})
+	if !ok {
+		return "", errors.New("mapping argument not provided")
+	}
+
+	if mapped, ok := mapping[val]; ok {
+		return fmt.Sprint(mapped), nil
+	}
+
+	return argStringOpt(args, "default", val)
+}
+
+// Comparison operators of the conditional parsers
+const (
+	CmpEq       = "eq"       // equal
+	CmpNe       = "ne"       // not equal
+	CmpGt       = "gt"       // greater than
+	CmpLt       = "lt"       // less than
+	CmpGe       = "ge"       // greater than or equal
+	CmpLe       = "le"       // less than or equal
+	CmpEmpty    = "empty"    // empty or blank, the value compared to is ignored
+	CmpNotEmpty = "notEmpty" // neither empty nor blank, the value compared to is ignored
+)
+
+// compareValues compares val to other with the operator. Both values are compared as numbers
+// if they are numbers, and as strings otherwise
+func compareValues(operator string, val string, other string) (bool, error) {
+	switch operator {
+	case CmpEmpty:
+		return strings.TrimSpace(val) == "", nil
+	case CmpNotEmpty:
+		return strings.TrimSpace(val) != "", nil
+	}
+
+	cmp := strings.Compare(val, other)
+
+	f1, err1 := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	f2, err2 := strconv.ParseFloat(strings.TrimSpace(other), 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case f1 < f2:
+			cmp = -1
+		case f1 > f2:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+
+	switch operator {
+	case CmpEq:
+		return cmp == 0, nil
+	case CmpNe:
+		return cmp != 0, nil
+	case CmpGt:
+		return cmp > 0, nil
+	case CmpLt:
+		return cmp < 0, nil
+	case CmpGe:
+		return cmp >= 0, nil
+	case CmpLe:
+		return cmp <= 0, nil
+	}
+
+	return false, fmt.Errorf("operator must be one of '%s', '%s', '%s', '%s', '%s', '%s', '%s' or '%s', not '%s'",
+		CmpEq, CmpNe, CmpGt, CmpLt, CmpGe, CmpLe, CmpEmpty, CmpNotEmpty, operator)
+}
+
+var ifThenParser = &Parser{
+	name:   "ifThen",
+	parser: ifThen,
+	args: ArgDef{
+		"value":      reflect.TypeOf(""),
+		"operator":   reflect.TypeOf(""),
+		"compareTo":  reflect.TypeOf(""),
+		"trueValue":  reflect.TypeOf(""),
+		"falseValue": reflect.TypeOf(""),
+	},
+}
+
+// ifThen compares the value to compareTo, a fixed value or another column, with the operator and
+// returns trueValue if the condition is met, or falseValue otherwise
+func ifThen(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var operator string
+	if operator, err = argStringOpt(args, "operator", CmpEq); err != nil {
+		return "", err
+	}
+
+	var other string
+	if other, err = argStringOpt(args, "compareTo", ""); err != nil {
+		return "", err
+	}
+
+	var trueVal string
+	if trueVal, err = argStringOpt(args, "trueValue", "true"); err != nil {
+		return "", err
+	}
+
+	var falseVal string
+	if falseVal, err = argStringOpt(args, "falseValue", "false"); err != nil {
+		return "", err
+	}
+
+	ok, err := compareValues(operator, val, other)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return trueVal, nil
+	}
+
+	return falseVal, nil
+}
+
+var switchParser = &Parser{
+	name:   "switch",
+	parser: switchCase,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"cases":   reflect.TypeOf([]interface{}{}),
+		"default": reflect.TypeOf(""),
+	},
+}
+
+// switchCase returns the result of the first of the cases whose condition the value meets. Cases are
+// written as 'operator operand => result', eg. 'lt 18 => minor' or 'empty => unknown', with the operators
+// of ifThen. The default value is returned if no condition is met, or the value unchanged if not provided
+func switchCase(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	cases, ok := args["cases"].([]interface{})
+	if !ok {
+		return "", errors.New("cases argument not provided")
+	}
+
+	for _, c := range cases {
+		caseStr := fmt.Sprint(c)
+
+		cond, result, ok := strings.Cut(caseStr, "=>")
+		if !ok {
+			return "", fmt.Errorf("case '%s' must be formatted as 'operator operand => result'", caseStr)
+		}
+
+		operator, operand, _ := strings.Cut(strings.TrimSpace(cond), " ")
+
+		met, err := compareValues(operator, val, strings.TrimSpace(operand))
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid case '%s'", caseStr)
+		}
+
+		if met {
+			return strings.TrimSpace(result), nil
+		}
+	}
+
+	return argStringOpt(args, "default", val)
+}
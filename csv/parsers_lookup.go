@@ -0,0 +1,138 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// lookupTables holds the tables of the lookup parser mapped by file, so that each file is
+// loaded once per process rather than for each row
+var lookupTables sync.Map
+
+// lookupEntry is the key to value table loaded from a lookup file
+type lookupEntry struct {
+	once  sync.Once
+	table map[string]string
+	err   error
+}
+
+// lookupTable returns the table loaded from the file, loading it if needed. Rows looking up
+// a file being loaded wait for it, the others are not blocked
+func lookupTable(ctx context.Context, file string) (map[string]string, error) {
+	v, _ := lookupTables.LoadOrStore(file, &lookupEntry{})
+	entry := v.(*lookupEntry)
+
+	entry.once.Do(func() {
+		entry.table, entry.err = loadLookupTable(ctx, file)
+	})
+
+	if entry.err != nil {
+		// failed loads are not kept, eg. if the run was cancelled, the next rows try again
+		lookupTables.CompareAndDelete(file, entry)
+		return nil, errors.Wrapf(entry.err, "could not load lookup file '%s'", file)
+	}
+
+	return entry.table, nil
+}
+
+// loadLookupTable reads the file whose first column is the key and second column is the value.
+// The first row is the header and is skipped. When a key appears more than once, the first value is kept
+func loadLookupTable(ctx context.Context, file string) (map[string]string, error) {
+	f, err := openFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, closer, err := newCsvReader(f, &InputConf{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(header) < 2 {
+		return nil, errors.New("the file must have a key and a value column")
+	}
+
+	table := map[string]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) < 2 {
+			continue
+		}
+
+		if _, ok := table[record[0]]; !ok {
+			table[record[0]] = record[1]
+		}
+	}
+
+	return table, nil
+}
+
+var lookupParser = &Parser{
+	name:   "lookup",
+	parser: lookup,
+	args: ArgDef{
+		"value":     reflect.TypeOf(""),
+		"file":      reflect.TypeOf(""),
+		"onMissing": reflect.TypeOf(""),
+		"default":   reflect.TypeOf(""),
+	},
+}
+
+// lookup maps the value through the key and value columns of the file. Values missing from the file
+// are replaced by the default value if provided, otherwise they are kept ('keep', by default), emptied
+// ('empty') or the parser fails ('abort') according to onMissing
+func lookup(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var file string
+	if file, err = argString(args, "file"); err != nil {
+		return "", err
+	}
+
+	var onMissing string
+	if onMissing, err = argStringOpt(args, "onMissing", OnErrorKeep); err != nil {
+		return "", err
+	}
+
+	if onMissing != OnErrorKeep && onMissing != OnErrorEmpty && onMissing != OnErrorAbort {
+		return "", fmt.Errorf("onMissing must either be '%s', '%s' or '%s'", OnErrorKeep, OnErrorEmpty, OnErrorAbort)
+	}
+
+	table, err := lookupTable(ctx, file)
+	if err != nil {
+		return "", err
+	}
+
+	if mapped, ok := table[val]; ok {
+		return mapped, nil
+	}
+
+	if _, ok := args["default"]; ok {
+		return argString(args, "default")
+	}
+
+	return onParseError(onMissing, val, fmt.Errorf("value '%s' not found in '%s'", val, file))
+}
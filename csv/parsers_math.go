@@ -0,0 +1,222 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/expr-lang/expr"
+	"github.com/pkg/errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// programCache holds the expressions compiled by the parsers, mapped by code
+var programCache = newCompileCache(compileExpr)
+
+var mathParser = &Parser{
+	name:   "math",
+	parser: mathExpr,
+	args: ArgDef{
+		"expression": reflect.TypeOf(""),
+		"vars":       reflect.TypeOf(""),
+		"values":     reflect.TypeOf([]interface{}{}),
+		"precision":  reflect.TypeOf(""),
+	},
+}
+
+// mathExpr evaluates the arithmetic expression, eg. 'price * qty * (1 + taxRate)', in which the
+// comma separated vars are bound to the numeric values in order. Empty values are 0. The result
+// is rounded to the precision if provided
+func mathExpr(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var code string
+	if code, err = argString(args, "expression"); err != nil {
+		return "", err
+	}
+
+	var vars string
+	if vars, err = argStringOpt(args, "vars", ""); err != nil {
+		return "", err
+	}
+
+	var precision int
+	if precision, err = argIntOpt(args, "precision", -1); err != nil {
+		return "", err
+	}
+
+	var values []interface{}
+	if valuesI, ok := args["values"]; ok {
+		values = valuesI.([]interface{})
+	}
+
+	var names []string
+	if vars != "" {
+		names = strings.Split(vars, ",")
+	}
+
+	if len(names) != len(values) {
+		return "", fmt.Errorf("expected %d values for the vars '%s', got %d", len(names), vars, len(values))
+	}
+
+	env := map[string]interface{}{}
+	for i, name := range names {
+		vStr := strings.TrimSpace(fmt.Sprint(values[i]))
+		if vStr == "" {
+			env[strings.TrimSpace(name)] = 0.0
+			continue
+		}
+
+		f, err := strconv.ParseFloat(vStr, 64)
+		if err != nil {
+			return "", fmt.Errorf("value of '%s' is not a number. vStr: '%s'", name, vStr)
+		}
+		env[strings.TrimSpace(name)] = f
+	}
+
+	program, err := programCache.get(code)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := expr.Run(program, env)
+	if err != nil {
+		return "", errors.Wrapf(err, "error evaluating '%s'", code)
+	}
+
+	switch v := out.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', precision, 64), nil
+	case int:
+		return strconv.FormatFloat(float64(v), 'f', precision, 64), nil
+	case nil:
+		return "", nil
+	}
+
+	return fmt.Sprint(out), nil
+}
+
+// Rounding modes of the rounding parsers
+const (
+	RoundHalfUp   = "halfUp"   // halves are rounded away from zero
+	RoundHalfEven = "halfEven" // halves are rounded to the even neighbour, aka banker's rounding
+	roundFloor    = "floor"
+	roundCeil     = "ceil"
+	roundTruncate = "truncate"
+)
+
+var roundParser = &Parser{
+	name:   "round",
+	parser: roundValue(""),
+	args:   ArgDef{"value": reflect.TypeOf(""), "precision": reflect.TypeOf(""), "mode": reflect.TypeOf("")},
+}
+
+var floorParser = &Parser{
+	name:   "floor",
+	parser: roundValue(roundFloor),
+	args:   ArgDef{"value": reflect.TypeOf(""), "precision": reflect.TypeOf("")},
+}
+
+var ceilParser = &Parser{
+	name:   "ceil",
+	parser: roundValue(roundCeil),
+	args:   ArgDef{"value": reflect.TypeOf(""), "precision": reflect.TypeOf("")},
+}
+
+var truncateParser = &Parser{
+	name:   "truncate",
+	parser: roundValue(roundTruncate),
+	args:   ArgDef{"value": reflect.TypeOf(""), "precision": reflect.TypeOf("")},
+}
+
+// roundValue returns the parse function rounding the number to the precision, the number of decimals
+// kept, 0 by default. A negative precision rounds to tens, hundreds... The round parser takes its
+// mode from the mode argument. Numbers are rounded exactly from their decimal representation
+func roundValue(mode string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		var err error
+
+		var val string
+		if val, err = argString(args, "value"); err != nil {
+			return "", err
+		}
+
+		var precision int
+		if precision, err = argIntOpt(args, "precision", 0); err != nil {
+			return "", err
+		}
+
+		roundMode := mode
+		if roundMode == "" {
+			if roundMode, err = argStringOpt(args, "mode", RoundHalfUp); err != nil {
+				return "", err
+			}
+
+			if roundMode != RoundHalfUp && roundMode != RoundHalfEven {
+				return "", fmt.Errorf("mode must either be '%s' or '%s'", RoundHalfUp, RoundHalfEven)
+			}
+		}
+
+		if strings.TrimSpace(val) == "" {
+			return "", nil
+		}
+
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(val))
+		if !ok {
+			return "", fmt.Errorf("not a number. vStr: '%s'", val)
+		}
+
+		decimals := precision
+		if decimals < 0 {
+			decimals = 0
+		}
+
+		return roundRat(r, precision, roundMode).FloatString(decimals), nil
+	}
+}
+
+// roundRat rounds the number to the precision according to the mode
+func roundRat(r *big.Rat, precision int, mode string) *big.Rat {
+	exp := precision
+	if exp < 0 {
+		exp = -exp
+	}
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+
+	scaled := new(big.Rat)
+	if precision >= 0 {
+		scaled.Mul(r, scale)
+	} else {
+		scaled.Quo(r, scale)
+	}
+
+	// the quotient is truncated towards zero, the remainder having the sign of the number
+	q, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	away := big.NewInt(int64(scaled.Sign()))
+
+	switch mode {
+	case roundFloor:
+		if rem.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		}
+	case roundCeil:
+		if rem.Sign() > 0 {
+			q.Add(q, big.NewInt(1))
+		}
+	case RoundHalfUp, RoundHalfEven:
+		// comparing twice the remainder to the denominator tells whether it is above, below or at half
+		half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom())
+		if half > 0 || (half == 0 && (mode == RoundHalfUp || q.Bit(0) == 1)) {
+			q.Add(q, away)
+		}
+	}
+
+	out := new(big.Rat).SetInt(q)
+	if precision >= 0 {
+		return out.Quo(out, scale)
+	}
+
+	return out.Mul(out, scale)
+}
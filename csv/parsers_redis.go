@@ -0,0 +1,138 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"reflect"
+	"sync"
+)
+
+// redisClients holds one client per redis address so that connections are
+// pooled across rows
+var redisClients = struct {
+	sync.Mutex
+	m map[string]*redis.Client
+}{m: map[string]*redis.Client{}}
+
+// redisClient returns the client connected to the given address, creating it if needed
+func redisClient(addr string, password string, db int) *redis.Client {
+	redisClients.Lock()
+	defer redisClients.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%d", addr, password, db)
+	client, ok := redisClients.m[key]
+	if !ok {
+		client = redis.NewClient(&redis.Options{Addr: addr, Password: password, DB: db})
+		redisClients.m[key] = client
+	}
+
+	return client
+}
+
+var redisGetParser = &Parser{
+	name:   "redisGet",
+	parser: redisGet,
+	args: ArgDef{
+		"value":    reflect.TypeOf(""),
+		"addr":     reflect.TypeOf(""),
+		"password": reflect.TypeOf(""),
+		"db":       reflect.TypeOf(""),
+		"prefix":   reflect.TypeOf(""),
+		"default":  reflect.TypeOf(""),
+	},
+}
+
+var redisSetParser = &Parser{
+	name:   "redisSet",
+	parser: redisSet,
+	args: ArgDef{
+		"value":    reflect.TypeOf(""),
+		"addr":     reflect.TypeOf(""),
+		"password": reflect.TypeOf(""),
+		"db":       reflect.TypeOf(""),
+		"prefix":   reflect.TypeOf(""),
+		"key":      reflect.TypeOf(""),
+	},
+}
+
+// redisArgs returns the client and the key prefix from the arguments shared by the redis parsers
+func redisArgs(args FuncArgs) (*redis.Client, string, error) {
+	var err error
+
+	var addr string
+	if addr, err = argStringOpt(args, "addr", "localhost:6379"); err != nil {
+		return nil, "", err
+	}
+
+	var password string
+	if password, err = argStringOpt(args, "password", ""); err != nil {
+		return nil, "", err
+	}
+
+	var db int
+	if db, err = argIntOpt(args, "db", 0); err != nil {
+		return nil, "", err
+	}
+
+	var prefix string
+	if prefix, err = argStringOpt(args, "prefix", ""); err != nil {
+		return nil, "", err
+	}
+
+	return redisClient(addr, password, db), prefix, nil
+}
+
+// redisGet looks up the key made of prefix and value, and returns the stored
+// value or the default if the key does not exist
+func redisGet(ctx context.Context, args FuncArgs) (string, error) {
+	client, prefix, err := redisArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var def string
+	if def, err = argStringOpt(args, "default", ""); err != nil {
+		return "", err
+	}
+
+	out, err := client.Get(ctx, prefix+val).Result()
+	if err == redis.Nil {
+		return def, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return out, nil
+}
+
+// redisSet writes the value under the key made of prefix and key, and returns
+// the value unchanged
+func redisSet(ctx context.Context, args FuncArgs) (string, error) {
+	client, prefix, err := redisArgs(args)
+	if err != nil {
+		return "", err
+	}
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var key string
+	if key, err = argString(args, "key"); err != nil {
+		return "", err
+	}
+
+	if err = client.Set(ctx, prefix+key, val, 0).Err(); err != nil {
+		return "", err
+	}
+
+	return val, nil
+}
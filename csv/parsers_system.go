@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var envParser = &Parser{
+	name:   "env",
+	parser: env,
+	args:   ArgDef{"name": reflect.TypeOf(""), "default": reflect.TypeOf("")},
+}
+
+// env returns the value of the environment variable, or the default value, empty if not provided,
+// when the variable is not set or empty
+func env(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var name string
+	if name, err = argString(args, "name"); err != nil {
+		return "", err
+	}
+
+	if val := os.Getenv(name); val != "" {
+		return val, nil
+	}
+
+	return argStringOpt(args, "default", "")
+}
+
+var fileSizeParser = &Parser{
+	name:   "fileSize",
+	parser: fileSize,
+	args:   ArgDef{"filename": reflect.TypeOf("")},
+}
+
+// fileSize returns the size of the file in bytes, or an empty value if the file does not exist
+func fileSize(ctx context.Context, args FuncArgs) (string, error) {
+	fileName, err := argString(args, "filename")
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(fileName)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(info.Size(), 10), nil
+}
+
+var fileMtimeParser = &Parser{
+	name:   "fileMtime",
+	parser: fileMtime,
+	args:   ArgDef{"filename": reflect.TypeOf(""), "layout": reflect.TypeOf("")},
+}
+
+// fileMtime returns the modification time of the file formatted with the layout, RFC 3339 by default,
+// or an empty value if the file does not exist
+func fileMtime(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var fileName string
+	if fileName, err = argString(args, "filename"); err != nil {
+		return "", err
+	}
+
+	var layout string
+	if layout, err = argStringOpt(args, "layout", time.RFC3339); err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(fileName)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return info.ModTime().Format(layout), nil
+}
@@ -0,0 +1,68 @@
+package csv
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// parsedRowKey is the context key of the row being parsed
+type parsedRowKey struct{}
+
+// withRow returns the context of the parsers run on the row, giving them access to all its values
+func withRow(ctx context.Context, row Row) context.Context {
+	return context.WithValue(ctx, parsedRowKey{}, row)
+}
+
+// rowFromContext returns the row being parsed, or nil if the parser is not run on a row
+func rowFromContext(ctx context.Context) Row {
+	row, _ := ctx.Value(parsedRowKey{}).(Row)
+	return row
+}
+
+// templateCache holds the templates parsed by the template parser, mapped by text
+var templateCache = newCompileCache(func(text string) (*template.Template, error) {
+	tpl, err := template.New("template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid template '%s'", text)
+	}
+
+	return tpl, nil
+})
+
+var templateParser = &Parser{
+	name:   "template",
+	parser: renderTemplate,
+	args:   ArgDef{"template": reflect.TypeOf("")},
+}
+
+// renderTemplate renders the Go text/template with the values of the row mapped by column name,
+// eg. '{{.first_name}} {{.last_name}} <{{.email}}>'. Dynamic columns are only available once their
+// parsers have run, so templates should refer to the columns read from the input
+func renderTemplate(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var text string
+	if text, err = argString(args, "template"); err != nil {
+		return "", err
+	}
+
+	tpl, err := templateCache.get(text)
+	if err != nil {
+		return "", err
+	}
+
+	data := map[string]string{}
+	for col, val := range rowFromContext(ctx) {
+		data[col] = val.ValStr()
+	}
+
+	var out strings.Builder
+	if err := tpl.Execute(&out, data); err != nil {
+		return "", errors.Wrap(err, "error rendering template")
+	}
+
+	return out.String(), nil
+}
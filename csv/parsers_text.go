@@ -0,0 +1,789 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// regexpCache holds the regular expressions compiled by the parsers, mapped by pattern
+var regexpCache = newCompileCache(func(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern '%s'", pattern)
+	}
+
+	return re, nil
+})
+
+var regexReplaceParser = &Parser{
+	name:   "regexReplace",
+	parser: regexReplace,
+	args: ArgDef{
+		"value":       reflect.TypeOf(""),
+		"pattern":     reflect.TypeOf(""),
+		"replacement": reflect.TypeOf(""),
+	},
+}
+
+// regexReplace replaces the matches of the pattern in the value by the replacement, in which
+// capture groups are referenced as $1 or ${name}. The matches are removed if no replacement is provided
+func regexReplace(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var pattern string
+	if pattern, err = argString(args, "pattern"); err != nil {
+		return "", err
+	}
+
+	var replacement string
+	if replacement, err = argStringOpt(args, "replacement", ""); err != nil {
+		return "", err
+	}
+
+	re, err := regexpCache.get(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(val, replacement), nil
+}
+
+// Sides of the value the trim parsers remove characters from
+const (
+	trimBoth = iota
+	trimLeft
+	trimRight
+)
+
+var trimParser = &Parser{
+	name:   "trim",
+	parser: trimValue(trimBoth),
+	args:   ArgDef{"value": reflect.TypeOf(""), "cutset": reflect.TypeOf("")},
+}
+
+var ltrimParser = &Parser{
+	name:   "ltrim",
+	parser: trimValue(trimLeft),
+	args:   ArgDef{"value": reflect.TypeOf(""), "cutset": reflect.TypeOf("")},
+}
+
+var rtrimParser = &Parser{
+	name:   "rtrim",
+	parser: trimValue(trimRight),
+	args:   ArgDef{"value": reflect.TypeOf(""), "cutset": reflect.TypeOf("")},
+}
+
+// trimValue returns the parse function removing the characters of the cutset from the given side(s)
+// of the value. White spaces are removed if no cutset is provided
+func trimValue(side int) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		var err error
+
+		var val string
+		if val, err = argString(args, "value"); err != nil {
+			return "", err
+		}
+
+		var cutset string
+		if cutset, err = argStringOpt(args, "cutset", ""); err != nil {
+			return "", err
+		}
+
+		if cutset == "" {
+			switch side {
+			case trimLeft:
+				return strings.TrimLeftFunc(val, unicode.IsSpace), nil
+			case trimRight:
+				return strings.TrimRightFunc(val, unicode.IsSpace), nil
+			}
+			return strings.TrimSpace(val), nil
+		}
+
+		switch side {
+		case trimLeft:
+			return strings.TrimLeft(val, cutset), nil
+		case trimRight:
+			return strings.TrimRight(val, cutset), nil
+		}
+		return strings.Trim(val, cutset), nil
+	}
+}
+
+var padLeftParser = &Parser{
+	name:   "padLeft",
+	parser: padValue(true),
+	args:   ArgDef{"value": reflect.TypeOf(""), "length": reflect.TypeOf(""), "pad": reflect.TypeOf("")},
+}
+
+var padRightParser = &Parser{
+	name:   "padRight",
+	parser: padValue(false),
+	args:   ArgDef{"value": reflect.TypeOf(""), "length": reflect.TypeOf(""), "pad": reflect.TypeOf("")},
+}
+
+// padValue returns the parse function padding the value on the left or on the right with the pad
+// character, a space by default, up to the length. Longer values are returned unchanged
+func padValue(left bool) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		var err error
+
+		var val string
+		if val, err = argString(args, "value"); err != nil {
+			return "", err
+		}
+
+		var length int
+		if length, err = argInt(args, "length"); err != nil {
+			return "", err
+		}
+
+		var pad string
+		if pad, err = argStringOpt(args, "pad", " "); err != nil {
+			return "", err
+		}
+
+		if utf8.RuneCountInString(pad) != 1 {
+			return "", fmt.Errorf("pad must be a single character, got '%s'", pad)
+		}
+
+		n := length - utf8.RuneCountInString(val)
+		if n <= 0 {
+			return val, nil
+		}
+
+		if left {
+			return strings.Repeat(pad, n) + val, nil
+		}
+
+		return val + strings.Repeat(pad, n), nil
+	}
+}
+
+var fixedLengthParser = &Parser{
+	name:   "fixedLength",
+	parser: fixedLength,
+	args: ArgDef{
+		"value":  reflect.TypeOf(""),
+		"length": reflect.TypeOf(""),
+		"pad":    reflect.TypeOf(""),
+		"side":   reflect.TypeOf(""),
+	},
+}
+
+// fixedLength pads the value up to the length with the pad character, '0' by default, on the left
+// or on the right according to side, eg. to restore the leading zeros of account numbers and postal
+// codes. Unlike padLeft and padRight, longer values are cut to the length, keeping their first characters
+func fixedLength(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var side string
+	if side, err = argStringOpt(args, "side", "left"); err != nil {
+		return "", err
+	}
+
+	if side != "left" && side != "right" {
+		return "", fmt.Errorf("side must either be 'left' or 'right', not '%s'", side)
+	}
+
+	padArgs := FuncArgs{"pad": "0"}
+	for name, arg := range args {
+		padArgs[name] = arg
+	}
+
+	val, err := padValue(side == "left")(ctx, padArgs)
+	if err != nil {
+		return "", err
+	}
+
+	length, _ := argInt(args, "length")
+	if runes := []rune(val); length >= 0 && len(runes) > length {
+		return string(runes[:length]), nil
+	}
+
+	return val, nil
+}
+
+var substringParser = &Parser{
+	name:   "substring",
+	parser: substring,
+	args:   ArgDef{"value": reflect.TypeOf(""), "start": reflect.TypeOf(""), "length": reflect.TypeOf("")},
+}
+
+// substring returns the characters of the value from the start index, starting at 0, up to the
+// length or to the end of the value. A negative start is counted from the end of the value
+func substring(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var start int
+	if start, err = argInt(args, "start"); err != nil {
+		return "", err
+	}
+
+	runes := []rune(val)
+
+	var length int
+	if length, err = argIntOpt(args, "length", len(runes)); err != nil {
+		return "", err
+	}
+
+	if length < 0 {
+		return "", errors.New("length cannot be negative")
+	}
+
+	if start < 0 {
+		start += len(runes)
+		if start < 0 {
+			start = 0
+		}
+	}
+
+	if start >= len(runes) {
+		return "", nil
+	}
+
+	end := start + length
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	return string(runes[start:end]), nil
+}
+
+var replaceParser = &Parser{
+	name:   "replace",
+	parser: replace,
+	args: ArgDef{
+		"value":      reflect.TypeOf(""),
+		"old":        reflect.TypeOf(""),
+		"new":        reflect.TypeOf(""),
+		"count":      reflect.TypeOf(""),
+		"ignoreCase": reflect.TypeOf(""),
+	},
+}
+
+// replace replaces the occurrences of old in the value by new, all of them or the first count ones.
+// The occurrences are removed if new is not provided
+func replace(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var old string
+	if old, err = argString(args, "old"); err != nil {
+		return "", err
+	}
+
+	var repl string
+	if repl, err = argStringOpt(args, "new", ""); err != nil {
+		return "", err
+	}
+
+	var count int
+	if count, err = argIntOpt(args, "count", -1); err != nil {
+		return "", err
+	}
+
+	var ignoreCase bool
+	if ignoreCase, err = argBoolOpt(args, "ignoreCase", false); err != nil {
+		return "", err
+	}
+
+	if !ignoreCase {
+		return strings.Replace(val, old, repl, count), nil
+	}
+
+	re, err := regexpCache.get("(?i)" + regexp.QuoteMeta(old))
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringIndex(val, count) {
+		sb.WriteString(val[last:loc[0]])
+		sb.WriteString(repl)
+		last = loc[1]
+	}
+	sb.WriteString(val[last:])
+
+	return sb.String(), nil
+}
+
+var splitIndexParser = &Parser{
+	name:   "splitIndex",
+	parser: splitIndex,
+	args: ArgDef{
+		"value":   reflect.TypeOf(""),
+		"sep":     reflect.TypeOf(""),
+		"index":   reflect.TypeOf(""),
+		"default": reflect.TypeOf(""),
+	},
+}
+
+// splitIndex splits the value by the separator and returns the element at the index, starting at 0.
+// A negative index is counted from the end. The default value is returned if the index is out of range
+func splitIndex(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var sep string
+	if sep, err = argString(args, "sep"); err != nil {
+		return "", err
+	}
+
+	var index int
+	if index, err = argInt(args, "index"); err != nil {
+		return "", err
+	}
+
+	var def string
+	if def, err = argStringOpt(args, "default", ""); err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(val, sep)
+	if index < 0 {
+		index += len(parts)
+	}
+
+	if index < 0 || index >= len(parts) {
+		return def, nil
+	}
+
+	return parts[index], nil
+}
+
+// Case styles of the case parsers, besides upper and lower case
+const (
+	caseTitle    = "title"
+	caseSentence = "sentence"
+	caseCamel    = "camel"
+	caseSnake    = "snake"
+)
+
+var titleCaseParser = &Parser{
+	name:   "titleCase",
+	parser: changeCaseStyle(caseTitle),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var sentenceCaseParser = &Parser{
+	name:   "sentenceCase",
+	parser: changeCaseStyle(caseSentence),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var camelCaseParser = &Parser{
+	name:   "camelCase",
+	parser: changeCaseStyle(caseCamel),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+var snakeCaseParser = &Parser{
+	name:   "snakeCase",
+	parser: changeCaseStyle(caseSnake),
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+// changeCaseStyle returns the parse function converting the value to the case style:
+// 'Title Case', 'Sentence case. Of each sentence', 'camelCase' or 'snake_case'
+func changeCaseStyle(style string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		val, err := argString(args, "value")
+		if err != nil {
+			return "", err
+		}
+
+		switch style {
+		case caseTitle:
+			return titleCase(val), nil
+		case caseSentence:
+			return sentenceCase(val), nil
+		case caseCamel:
+			words := splitWords(val)
+			for i, w := range words {
+				w = strings.ToLower(w)
+				if i > 0 {
+					w = upperFirst(w)
+				}
+				words[i] = w
+			}
+			return strings.Join(words, ""), nil
+		}
+
+		return strings.ToLower(strings.Join(splitWords(val), "_")), nil
+	}
+}
+
+// titleCase lower cases the value and upper cases the first letter of each word
+func titleCase(val string) string {
+	runes := []rune(strings.ToLower(val))
+	for i, r := range runes {
+		if i == 0 || !(unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1]) || runes[i-1] == '\'') {
+			runes[i] = unicode.ToUpper(r)
+		}
+	}
+
+	return string(runes)
+}
+
+// sentenceCase lower cases the value and upper cases the first letter of each sentence
+func sentenceCase(val string) string {
+	runes := []rune(strings.ToLower(val))
+	start := true
+	for i, r := range runes {
+		switch {
+		case r == '.' || r == '!' || r == '?':
+			start = true
+		case start && unicode.IsLetter(r):
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		case unicode.IsDigit(r):
+			start = false
+		}
+	}
+
+	return string(runes)
+}
+
+// upperFirst upper cases the first letter of the word
+func upperFirst(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// splitWords splits the value into words, separated by any character which is not a letter nor a digit,
+// or by a change of case, eg. 'HTTPServer error_code' is split into 'HTTP', 'Server', 'error' and 'code'
+func splitWords(val string) []string {
+	var words []string
+	var word []rune
+
+	runes := []rune(val)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return words
+}
+
+var slugifyParser = &Parser{
+	name:   "slugify",
+	parser: slugify,
+	args:   ArgDef{"value": reflect.TypeOf(""), "sep": reflect.TypeOf("")},
+}
+
+// slugify converts the value into a URL-safe slug, eg. 'Crème Brûlée & Co.' becomes 'creme-brulee-co'.
+// Letters are transliterated to ASCII, and the other characters which are not letters nor digits are replaced by the separator
+func slugify(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var sep string
+	if sep, err = argStringOpt(args, "sep", "-"); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	pendingSep := false
+	for _, r := range transliterate(strings.ToLower(val), true) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingSep && sb.Len() > 0 {
+				sb.WriteString(sep)
+			}
+			pendingSep = false
+			sb.WriteRune(r)
+			continue
+		}
+
+		pendingSep = true
+	}
+
+	return sb.String(), nil
+}
+
+var transliterateParser = &Parser{
+	name:   "transliterate",
+	parser: transliterateValue,
+	args:   ArgDef{"value": reflect.TypeOf(""), "dropUnknown": reflect.TypeOf("")},
+}
+
+// transliterateValue converts the accented and non-Latin characters of the value to ASCII, eg. 'Müller' becomes
+// 'Muller' and 'Straße' becomes 'Strasse'. The characters which cannot be transliterated are kept, or dropped if
+// dropUnknown is true
+func transliterateValue(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var dropUnknown bool
+	if dropUnknown, err = argBoolOpt(args, "dropUnknown", false); err != nil {
+		return "", err
+	}
+
+	return transliterate(val, dropUnknown), nil
+}
+
+// transliterations maps the characters which are not decomposed into an ASCII letter and diacritical marks
+// to their ASCII transliteration
+var transliterations = map[rune]string{
+	'ß': "ss", 'ẞ': "SS", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "Th", 'ł': "l", 'Ł': "L",
+	'ı': "i", 'ħ': "h", 'Ħ': "H", 'ŋ': "ng", 'Ŋ': "NG", 'ĸ': "q", 'ſ': "s",
+	'‘': "'", '’': "'", '‚': "'", '“': "\"", '”': "\"", '„': "\"", '«': "\"", '»': "\"",
+	'–': "-", '—': "-", '…': "...", '\u00a0': " ", '€': "EUR", '£': "GBP", '©': "(c)", '®': "(r)", '™': "TM",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D", 'Е': "E", 'Ё': "E", 'Ж': "Zh", 'З': "Z", 'И': "I",
+	'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N", 'О': "O", 'П': "P", 'Р': "R", 'С': "S", 'Т': "T",
+	'У': "U", 'Ф': "F", 'Х': "Kh", 'Ц': "Ts", 'Ч': "Ch", 'Ш': "Sh", 'Щ': "Shch", 'Ъ': "", 'Ы': "Y", 'Ь': "",
+	'Э': "E", 'Ю': "Yu", 'Я': "Ya", 'І': "I", 'Ї': "Yi", 'Є': "Ye", 'Ґ': "G",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	'Α': "A", 'Β': "V", 'Γ': "G", 'Δ': "D", 'Ε': "E", 'Ζ': "Z", 'Η': "I", 'Θ': "Th", 'Ι': "I", 'Κ': "K",
+	'Λ': "L", 'Μ': "M", 'Ν': "N", 'Ξ': "X", 'Ο': "O", 'Π': "P", 'Ρ': "R", 'Σ': "S", 'Τ': "T",
+	'Υ': "Y", 'Φ': "F", 'Χ': "Ch", 'Ψ': "Ps", 'Ω': "O",
+}
+
+// transliterate removes the diacritical marks of the letters and converts the other non-ASCII characters
+// with the transliterations table. The characters left are kept, or dropped if drop is true
+func transliterate(val string, drop bool) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(val) {
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+		default:
+			if t, ok := transliterations[r]; ok {
+				sb.WriteString(t)
+			} else if !drop {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	return norm.NFC.String(sb.String())
+}
+
+var lengthParser = &Parser{
+	name:   "length",
+	parser: length,
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+// length returns the number of characters of the value
+func length(ctx context.Context, args FuncArgs) (string, error) {
+	val, err := argString(args, "value")
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(utf8.RuneCountInString(val)), nil
+}
+
+var wordCountParser = &Parser{
+	name:   "wordCount",
+	parser: wordCount,
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+// wordCount returns the number of words of the value, words being separated by white spaces
+func wordCount(ctx context.Context, args FuncArgs) (string, error) {
+	val, err := argString(args, "value")
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(len(strings.Fields(val))), nil
+}
+
+// htmlBlockTags are the elements separating their text from the surrounding text when stripping HTML
+var htmlBlockTags = map[string]bool{
+	"br": true, "p": true, "div": true, "li": true, "ul": true, "ol": true, "tr": true, "td": true, "th": true,
+	"table": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true, "hr": true,
+}
+
+var stripHtmlParser = &Parser{
+	name:   "stripHtml",
+	parser: stripHtml,
+	args:   ArgDef{"value": reflect.TypeOf("")},
+}
+
+// stripHtml removes the HTML tags, comments, scripts and styles from the value and decodes its entities,
+// eg. '<p>Fish &amp; <b>chips</b></p>' becomes 'Fish & chips'. Consecutive white spaces are collapsed
+func stripHtml(ctx context.Context, args FuncArgs) (string, error) {
+	val, err := argString(args, "value")
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	var skip bool
+
+	z := html.NewTokenizer(strings.NewReader(val))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		switch tt {
+		case html.TextToken:
+			if !skip {
+				out.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+
+			if tag == "script" || tag == "style" {
+				skip = tt == html.StartTagToken
+			}
+
+			if htmlBlockTags[tag] {
+				out.WriteByte(' ')
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(out.String()), " "), nil
+}
+
+// Strategies of the mask parser
+const (
+	MaskKeepLast  = "keepLast"  // eg. '************4242'
+	MaskKeepFirst = "keepFirst" // eg. '4242************'
+	MaskFull      = "full"      // eg. '****************'
+	MaskEmail     = "email"     // eg. 'j*******@example.com'
+)
+
+var maskParser = &Parser{
+	name:   "mask",
+	parser: mask,
+	args: ArgDef{
+		"value":    reflect.TypeOf(""),
+		"strategy": reflect.TypeOf(""),
+		"keep":     reflect.TypeOf(""),
+		"char":     reflect.TypeOf(""),
+	},
+}
+
+// mask replaces the characters of the value with the mask character, '*' by default, except the last
+// or the first ones according to the strategy, 4 by default. The email strategy keeps the first character
+// of the name and the domain of email addresses, other values being fully masked
+func mask(ctx context.Context, args FuncArgs) (string, error) {
+	var err error
+
+	var val string
+	if val, err = argString(args, "value"); err != nil {
+		return "", err
+	}
+
+	var strategy string
+	if strategy, err = argStringOpt(args, "strategy", MaskKeepLast); err != nil {
+		return "", err
+	}
+
+	var keep int
+	if keep, err = argIntOpt(args, "keep", 4); err != nil {
+		return "", err
+	}
+
+	var char string
+	if char, err = argStringOpt(args, "char", "*"); err != nil {
+		return "", err
+	}
+
+	if utf8.RuneCountInString(char) != 1 {
+		return "", fmt.Errorf("char must be a single character, got '%s'", char)
+	}
+
+	if keep < 0 {
+		return "", errors.New("keep cannot be negative")
+	}
+
+	runes := []rune(val)
+	if keep > len(runes) {
+		keep = len(runes)
+	}
+
+	switch strategy {
+	case MaskKeepLast:
+		return strings.Repeat(char, len(runes)-keep) + string(runes[len(runes)-keep:]), nil
+	case MaskKeepFirst:
+		return string(runes[:keep]) + strings.Repeat(char, len(runes)-keep), nil
+	case MaskFull:
+		return strings.Repeat(char, len(runes)), nil
+	case MaskEmail:
+		at := strings.LastIndex(val, "@")
+		if at < 1 {
+			return strings.Repeat(char, len(runes)), nil
+		}
+
+		name := []rune(val[:at])
+		return string(name[0]) + strings.Repeat(char, len(name)-1) + val[at:], nil
+	}
+
+	return "", fmt.Errorf("strategy must be one of '%s', '%s', '%s' or '%s'", MaskKeepLast, MaskKeepFirst, MaskFull, MaskEmail)
+}
@@ -0,0 +1,79 @@
+package csv
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"plugin"
+	"strings"
+)
+
+// Symbols looked up in plugins
+const (
+	PluginParsersSymbol    = "Parsers"
+	PluginOperationsSymbol = "Operations"
+)
+
+// LoadPlugin loads a Go plugin (.so file built with -buildmode=plugin) and registers the
+// parsers and operations it exports. The plugin exports them through the variables:
+//
+//	var Parsers []csv.ParserI
+//	var Operations []csv.Operation
+//
+// Both are optional, but a plugin must at least export one of them. The parsers and
+// operations are then validated against their ArgDef like the built-in ones. WASM modules
+// (.wasm files) are loaded by loadWasmPlugin
+func LoadPlugin(filename string) error {
+	if strings.ToLower(filepath.Ext(filename)) == ".wasm" {
+		return loadWasmPlugin(filename)
+	}
+
+	p, err := plugin.Open(filename)
+	if err != nil {
+		return errors.Wrapf(err, "error opening plugin '%s'", filename)
+	}
+
+	found := false
+
+	if sym, err := p.Lookup(PluginParsersSymbol); err == nil {
+		pluginParsers, ok := sym.(*[]ParserI)
+		if !ok {
+			return fmt.Errorf("'%s' must be of type []csv.ParserI in plugin '%s'", PluginParsersSymbol, filename)
+		}
+
+		for _, parser := range *pluginParsers {
+			if parser.Parser() == nil {
+				return fmt.Errorf("parser '%s' has no parse function in plugin '%s'", parser.Name(), filename)
+			}
+		}
+
+		if err = AddParsers(*pluginParsers...); err != nil {
+			return errors.Wrapf(err, "error loading plugin '%s'", filename)
+		}
+		found = true
+	}
+
+	if sym, err := p.Lookup(PluginOperationsSymbol); err == nil {
+		pluginOps, ok := sym.(*[]Operation)
+		if !ok {
+			return fmt.Errorf("'%s' must be of type []csv.Operation in plugin '%s'", PluginOperationsSymbol, filename)
+		}
+
+		for _, op := range *pluginOps {
+			if op.Name == "" || op.OpFunc == nil {
+				return fmt.Errorf("operations must have a name and a function in plugin '%s'", filename)
+			}
+		}
+
+		if err = AddOperations(*pluginOps...); err != nil {
+			return errors.Wrapf(err, "error loading plugin '%s'", filename)
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("plugin '%s' exports neither '%s' nor '%s'", filename, PluginParsersSymbol, PluginOperationsSymbol)
+	}
+
+	return nil
+}
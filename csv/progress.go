@@ -0,0 +1,183 @@
+package csv
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressInterval is how often the progress handlers are called during a run
+var ProgressInterval = 5 * time.Second
+
+// Progress is the state of a running run, passed periodically to the progress handlers
+type Progress struct {
+	File       string        // the CSV file being processed, empty when processing a reader
+	RowsRead   int           // number of rows read so far
+	Elapsed    time.Duration // time elapsed since the run started
+	RowsPerSec float64       // average number of rows read per second
+	Operation  string        // name of the operation running, empty while reading the rows
+	ETA        time.Duration // estimated time left to read the rows, 0 if unknown
+}
+
+// ProgressHandler is a function called periodically while a run is in progress
+type ProgressHandler func(progress Progress)
+
+// progressHandlers is the list of handlers called while a run is in progress
+var progressHandlers []ProgressHandler
+
+// OnProgress registers handlers called every ProgressInterval while a run is in progress
+func OnProgress(handlers ...ProgressHandler) {
+	progressHandlers = append(progressHandlers, handlers...)
+}
+
+// progressTracker tracks the progress of a run, it is safe to call its methods on a nil tracker
+type progressTracker struct {
+	file       string
+	start      time.Time
+	rows       int64 // accessed atomically
+	bytesRead  int64 // accessed atomically
+	totalBytes int64 // size of the input if known, accessed atomically
+
+	mu        sync.Mutex
+	operation string
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startProgress starts reporting the progress of the run to the progress handlers.
+// It returns nil if no handlers are registered
+func startProgress(summary *RunSummary) *progressTracker {
+	if len(progressHandlers) == 0 {
+		return nil
+	}
+
+	p := &progressTracker{
+		file:  summary.File,
+		start: summary.Start,
+		done:  make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(ProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.notify()
+			case <-p.done:
+				return
+			}
+		}
+	}()
+
+	return p
+}
+
+// stop stops reporting the progress
+func (p *progressTracker) stop() {
+	if p == nil {
+		return
+	}
+
+	close(p.done)
+	p.wg.Wait()
+}
+
+// notify calls all registered progress handlers with the current progress
+func (p *progressTracker) notify() {
+	progress := Progress{
+		File:     p.file,
+		RowsRead: int(atomic.LoadInt64(&p.rows)),
+		Elapsed:  time.Since(p.start),
+	}
+
+	if secs := progress.Elapsed.Seconds(); secs > 0 {
+		progress.RowsPerSec = float64(progress.RowsRead) / secs
+	}
+
+	p.mu.Lock()
+	progress.Operation = p.operation
+	p.mu.Unlock()
+
+	// estimating the time left from the share of the input read so far
+	read, total := atomic.LoadInt64(&p.bytesRead), atomic.LoadInt64(&p.totalBytes)
+	if progress.Operation == "" && read > 0 && total > read {
+		progress.ETA = time.Duration(float64(progress.Elapsed) * float64(total-read) / float64(read))
+	}
+
+	for _, handler := range progressHandlers {
+		handler(progress)
+	}
+}
+
+// addRows adds n to the number of rows read
+func (p *progressTracker) addRows(n int) {
+	if p == nil {
+		return
+	}
+
+	atomic.AddInt64(&p.rows, int64(n))
+}
+
+// setOperation sets the name of the operation running
+func (p *progressTracker) setOperation(name string) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.operation = name
+	p.mu.Unlock()
+}
+
+// setInputSize sets the size of the input used to estimate the time left, 0 if unknown
+func (p *progressTracker) setInputSize(filePaths []string) {
+	if p == nil {
+		return
+	}
+
+	var total int64
+	for _, fp := range filePaths {
+		// the size of stdin and remote files is unknown
+		if fp == StdStream {
+			return
+		}
+
+		info, err := os.Stat(fp)
+		if err != nil {
+			return
+		}
+		total += info.Size()
+	}
+
+	atomic.StoreInt64(&p.totalBytes, total)
+}
+
+// reader returns a reader counting the bytes read from r
+func (p *progressTracker) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+
+	return &countingReader{r: r, n: &p.bytesRead}
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (cr *countingReader) Read(b []byte) (int, error) {
+	n, err := cr.r.Read(b)
+	atomic.AddInt64(cr.n, int64(n))
+
+	return n, err
+}
@@ -0,0 +1,443 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats of the input data
+const (
+	FormatCsv       = "csv"
+	FormatXlsx      = "xlsx"
+	FormatJSON      = "json"  // an array of objects
+	FormatJSONLines = "jsonl" // one object per line
+	FormatParquet   = "parquet"
+	FormatFixed     = "fixed" // fixed-width lines, never detected
+)
+
+// zipMagic starts xlsx workbooks, which are zip archives
+var zipMagic = []byte("PK\x03\x04")
+
+// parquetMagic starts Parquet files
+var parquetMagic = []byte("PAR1")
+
+// recordReader reads the records of the input data one by one, the first one being the header,
+// as done by encoding/csv readers
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// validateFormat checks the format of the input configuration
+func validateFormat(conf *InputConf) error {
+	switch conf.Format {
+	case "", FormatCsv, FormatXlsx, FormatJSON, FormatJSONLines, FormatParquet, FormatFixed:
+		return nil
+	}
+
+	return fmt.Errorf("unsupported input format '%s'", conf.Format)
+}
+
+// xlsxReader reads the rows of a worksheet as records. The records are padded with empty
+// values to the length of the header, as trailing empty cells are not stored
+type xlsxReader struct {
+	f      *excelize.File
+	rows   *excelize.Rows
+	header int
+}
+
+// newXlsxReader opens the workbook and reads the rows of the sheet, the first sheet by default
+func newXlsxReader(in io.Reader, sheet string) (*xlsxReader, error) {
+	f, err := excelize.OpenReader(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening xlsx workbook")
+	}
+
+	if sheet == "" {
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			f.Close()
+			return nil, errors.New("no sheet found in the workbook")
+		}
+		sheet = sheets[0]
+	}
+
+	if idx, err := f.GetSheetIndex(sheet); err != nil || idx < 0 {
+		f.Close()
+		return nil, fmt.Errorf("sheet '%s' not found in the workbook", sheet)
+	}
+
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "error reading sheet '%s'", sheet)
+	}
+
+	return &xlsxReader{f: f, rows: rows}, nil
+}
+
+// Read returns the values of the next row as they are displayed, io.EOF once all rows are read
+func (r *xlsxReader) Read() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	rec, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if r.header == 0 {
+		r.header = len(rec)
+	}
+
+	for len(rec) < r.header {
+		rec = append(rec, "")
+	}
+
+	return rec, nil
+}
+
+// Close releases the workbook
+func (r *xlsxReader) Close() error {
+	r.rows.Close()
+	return r.f.Close()
+}
+
+// isJSON tells whether the data is JSON, either as configured, or detected from its first
+// character when no format is configured: '[' for arrays and '{' for JSON Lines
+func isJSON(r *bufio.Reader, conf *InputConf) bool {
+	if conf.Format != "" {
+		return conf.Format == FormatJSON || conf.Format == FormatJSONLines
+	}
+
+	c := firstChar(r)
+	return c == '[' || c == '{'
+}
+
+// firstChar returns the first character of the data which is not a space, without consuming it
+func firstChar(r *bufio.Reader) byte {
+	for i := 1; ; i++ {
+		b, err := r.Peek(i)
+		if err != nil {
+			return 0
+		}
+
+		if c := b[i-1]; c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c
+		}
+	}
+}
+
+// jsonReader reads JSON objects as records, either from an array or from JSON Lines. The header
+// holds the fields of all the objects in the order they first appear, so the objects are all
+// read on creation. Missing fields and nulls are read as empty values, and nested arrays and
+// objects as JSON unless flattened
+type jsonReader struct {
+	header  []string
+	records []map[string]string
+	next    int
+}
+
+// newJSONReader reads all the objects of the data
+func newJSONReader(r *bufio.Reader, flatten bool) (*jsonReader, error) {
+	isArray := firstChar(r) == '['
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	if isArray {
+		if _, err := dec.Token(); err != nil {
+			return nil, errors.Wrap(err, "error reading JSON array")
+		}
+	}
+
+	jr := &jsonReader{}
+	seen := map[string]bool{}
+
+	for i := 1; dec.More(); i++ {
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err != nil {
+			return nil, errors.Wrapf(err, "error reading JSON object %d", i)
+		}
+
+		rec := map[string]string{}
+		flattenJSON(obj, "", flatten, rec)
+
+		// the fields of each object are sorted, as the order of the fields is lost when decoding
+		var fields []string
+		for field := range rec {
+			if !seen[field] {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			seen[field] = true
+			jr.header = append(jr.header, field)
+		}
+
+		jr.records = append(jr.records, rec)
+	}
+
+	return jr, nil
+}
+
+// flattenJSON sets the values of the fields of the object in rec, prefixed by the given prefix.
+// The fields of nested objects are set as well when flattening
+func flattenJSON(obj map[string]interface{}, prefix string, flatten bool, rec map[string]string) {
+	for field, val := range obj {
+		name := prefix + field
+
+		if nested, ok := val.(map[string]interface{}); ok && flatten {
+			flattenJSON(nested, name+".", flatten, rec)
+			continue
+		}
+
+		rec[name] = jsonValueString(val)
+	}
+}
+
+// jsonValueString returns the decoded JSON value as a cell value. Nulls are empty,
+// and arrays and objects are encoded back to JSON
+func jsonValueString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	}
+
+	b, _ := json.Marshal(val)
+	return string(b)
+}
+
+// Read returns the header first, and then the values of the objects
+func (r *jsonReader) Read() ([]string, error) {
+	if r.next > len(r.records) {
+		return nil, io.EOF
+	}
+
+	r.next++
+	if r.next == 1 {
+		return r.header, nil
+	}
+
+	obj := r.records[r.next-2]
+
+	rec := make([]string, len(r.header))
+	for i, field := range r.header {
+		rec[i] = obj[field]
+	}
+
+	return rec, nil
+}
+
+// parquetReader reads the rows of a Parquet file as records, the columns being named after
+// their path, eg. 'address.city'. Repeated values are joined with commas
+type parquetReader struct {
+	r       *parquet.Reader
+	columns []parquet.Field
+	header  []string
+	buf     []parquet.Row
+}
+
+// newParquetReader opens the Parquet file, which is read entirely as Parquet files are read from their end
+func newParquetReader(in io.Reader) (*parquetReader, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening parquet file")
+	}
+
+	pr := &parquetReader{r: parquet.NewReader(f), buf: make([]parquet.Row, 1)}
+	for _, path := range f.Schema().Columns() {
+		leaf, ok := f.Schema().Lookup(path...)
+		if !ok {
+			return nil, fmt.Errorf("column '%s' not found in the parquet schema", strings.Join(path, "."))
+		}
+
+		field, ok := leaf.Node.(parquet.Field)
+		if !ok {
+			return nil, fmt.Errorf("column '%s' is not a field of the parquet schema", strings.Join(path, "."))
+		}
+
+		pr.columns = append(pr.columns, field)
+		pr.header = append(pr.header, strings.Join(path, "."))
+	}
+
+	return pr, nil
+}
+
+// Read returns the header first, and then the values of the rows
+func (r *parquetReader) Read() ([]string, error) {
+	if r.header != nil {
+		header := r.header
+		r.header = nil
+		return header, nil
+	}
+
+	n, err := r.r.ReadRows(r.buf)
+	if n == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+
+	vals := make([][]string, len(r.columns))
+	for _, v := range r.buf[0] {
+		if v.IsNull() {
+			continue
+		}
+
+		col := v.Column()
+		vals[col] = append(vals[col], parquetValue(v, r.columns[col]))
+	}
+
+	rec := make([]string, len(r.columns))
+	for i := range rec {
+		rec[i] = strings.Join(vals[i], ",")
+	}
+
+	return rec, nil
+}
+
+// Close releases the reader
+func (r *parquetReader) Close() error {
+	return r.r.Close()
+}
+
+// parquetValue returns the string representation of the value according to the logical type of its
+// column. Dates and timestamps are formatted as ISO 8601
+func parquetValue(v parquet.Value, col parquet.Field) string {
+	logical := ""
+	if lt := col.Type().LogicalType(); lt != nil {
+		logical = lt.String()
+	}
+
+	switch {
+	case logical == "DATE":
+		return time.Unix(int64(v.Int32())*86400, 0).UTC().Format("2006-01-02")
+	case strings.HasPrefix(logical, "TIMESTAMP"):
+		ts := v.Int64()
+		switch {
+		case strings.Contains(logical, "MILLIS"):
+			return time.Unix(0, ts*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano)
+		case strings.Contains(logical, "MICROS"):
+			return time.Unix(0, ts*int64(time.Microsecond)).UTC().Format(time.RFC3339Nano)
+		}
+		return time.Unix(0, ts).UTC().Format(time.RFC3339Nano)
+	}
+
+	switch v.Kind() {
+	case parquet.ByteArray, parquet.FixedLenByteArray:
+		return string(v.ByteArray())
+	}
+
+	return v.String()
+}
+
+// fixedColumn is the position of a column in fixed-width lines
+type fixedColumn struct {
+	name   string
+	start  int
+	length int
+}
+
+// fixedReader reads fixed-width lines as records, the values being cut at the positions of the
+// columns. The header is made of the names of the columns. Empty lines are skipped
+type fixedReader struct {
+	scanner *bufio.Scanner
+	fields  []fixedColumn
+	header  []string
+}
+
+// newFixedReader returns the reader of the fixed-width lines of in, split at the positions of the columns
+// defined with a start and a length, in the order of their start
+func newFixedReader(in io.Reader, defs ValueDefs) (*fixedReader, error) {
+	var fields []fixedColumn
+	for name, def := range defs {
+		if def.Dynamic || def.Length == 0 {
+			continue
+		}
+
+		if def.Start < 1 {
+			return nil, fmt.Errorf("start of col '%s' must be greater than 0", name)
+		}
+
+		fields = append(fields, fixedColumn{name: name, start: def.Start - 1, length: def.Length})
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("fixed-width input requires columns defined with a start and a length")
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].start < fields[j].start })
+
+	fr := &fixedReader{scanner: bufio.NewScanner(in), fields: fields}
+	fr.scanner.Buffer(nil, 1024*1024)
+	for _, f := range fields {
+		fr.header = append(fr.header, f.name)
+	}
+
+	return fr, nil
+}
+
+// Read returns the header first, and then the values of the lines
+func (r *fixedReader) Read() ([]string, error) {
+	if r.header != nil {
+		header := r.header
+		r.header = nil
+		return header, nil
+	}
+
+	for r.scanner.Scan() {
+		line := []rune(strings.TrimRight(r.scanner.Text(), "\r"))
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := make([]string, len(r.fields))
+		for i, f := range r.fields {
+			if f.start >= len(line) {
+				continue
+			}
+
+			end := f.start + f.length
+			if end > len(line) {
+				end = len(line)
+			}
+			rec[i] = string(line[f.start:end])
+		}
+
+		return rec, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
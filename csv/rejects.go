@@ -0,0 +1,71 @@
+package csv
+
+import (
+	"context"
+	gocsv "encoding/csv"
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// rejectedRow is a row which failed to parse and was left out of the run
+type rejectedRow struct {
+	source string // the file the row was read from
+	err    *RowError
+	rec    []string // the original record
+}
+
+// validateErrorPolicy validates the error policy of the input configuration
+func validateErrorPolicy(conf *InputConf) error {
+	switch conf.OnError {
+	case "", OnErrorAbort, OnErrorSkip, OnErrorCollect:
+	default:
+		return fmt.Errorf("onError must either be '%s', '%s' or '%s'", OnErrorAbort, OnErrorSkip, OnErrorCollect)
+	}
+
+	if conf.Rejects != "" && (conf.OnError == "" || conf.OnError == OnErrorAbort) {
+		return fmt.Errorf("rejects requires onError to be '%s' or '%s'", OnErrorSkip, OnErrorCollect)
+	}
+
+	return nil
+}
+
+// handleRejects writes the rejected rows to the rejects file if configured. With the
+// 'collect' policy, an error reporting all rejected rows is returned to stop the run
+func handleRejects(ctx context.Context, conf *InputConf, rejects []rejectedRow) error {
+	if conf.Rejects != "" {
+		if err := writeRejects(ctx, conf.Rejects, rejects); err != nil {
+			return err
+		}
+	}
+
+	if conf.OnError != OnErrorCollect || len(rejects) == 0 {
+		return nil
+	}
+
+	return errors.Wrapf(rejects[0].err, "%d rows failed to parse, first one", len(rejects))
+}
+
+// writeRejects writes the rejected rows to the file, each one preceded by the file it
+// was read from, its row number, the column which failed and the error message
+func writeRejects(ctx context.Context, filename string, rejects []rejectedRow) error {
+	wf, err := createFile(ctx, filename)
+	if err != nil {
+		return err
+	}
+
+	w := gocsv.NewWriter(wf)
+	w.Write([]string{"file", "row", "column", "error"})
+
+	for _, r := range rejects {
+		rec := []string{r.source, strconv.Itoa(r.err.RowIndex), r.err.Column, r.err.Err.Error()}
+		w.Write(append(rec, r.rec...))
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return abortFile(wf, err)
+	}
+
+	return wf.Close()
+}
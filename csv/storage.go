@@ -0,0 +1,531 @@
+package csv
+
+import (
+	"cloud.google.com/go/storage"
+	"context"
+	"fmt"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// URI schemes of the supported remote storages
+const (
+	SchemeGCS   = "gs://"
+	SchemeAzure = "azblob://"
+	SchemeSFTP  = "sftp://"
+	SchemeS3    = "s3://"
+	SchemeHTTP  = "http://"
+	SchemeHTTPS = "https://"
+)
+
+// StdStream is the location designating stdin when reading, and the output of the run when writing
+const StdStream = "-"
+
+// openFile opens the file at the given location for reading. The location is either
+// a local path, a remote URI (gs://bucket/object, azblob://container/blob,
+// sftp://user@host/path, s3://bucket/key, http(s)://host/path), or '-' for stdin
+func openFile(ctx context.Context, location string) (io.ReadCloser, error) {
+	switch {
+	case location == StdStream:
+		return ioutil.NopCloser(os.Stdin), nil
+	case strings.HasPrefix(location, SchemeGCS):
+		return openGCS(ctx, location)
+	case strings.HasPrefix(location, SchemeAzure):
+		return openAzure(ctx, location)
+	case strings.HasPrefix(location, SchemeSFTP):
+		return openSFTP(ctx, location)
+	case strings.HasPrefix(location, SchemeS3):
+		return openS3(ctx, location)
+	case strings.HasPrefix(location, SchemeHTTP), strings.HasPrefix(location, SchemeHTTPS):
+		return openHTTP(ctx, location)
+	}
+
+	return os.Open(location)
+}
+
+// createFile opens the file at the given location for writing. The location is either
+// a local path or a remote URI (gs://bucket/object, azblob://container/blob,
+// sftp://user@host/path, s3://bucket/key). Remote files are only complete once the writer is closed
+func createFile(ctx context.Context, location string) (io.WriteCloser, error) {
+	switch {
+	case strings.HasPrefix(location, SchemeGCS):
+		return createGCS(ctx, location)
+	case strings.HasPrefix(location, SchemeAzure):
+		return createAzure(ctx, location)
+	case strings.HasPrefix(location, SchemeSFTP):
+		return createSFTP(ctx, location)
+	case strings.HasPrefix(location, SchemeS3):
+		return createS3(ctx, location)
+	case strings.HasPrefix(location, SchemeHTTP), strings.HasPrefix(location, SchemeHTTPS):
+		return nil, fmt.Errorf("cannot write to '%s', http urls are read-only", location)
+	}
+
+	return os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0777)
+}
+
+// isLocal tells whether the location is a local path
+func isLocal(location string) bool {
+	if location == StdStream {
+		return false
+	}
+
+	for _, scheme := range []string{SchemeGCS, SchemeAzure, SchemeSFTP, SchemeS3, SchemeHTTP, SchemeHTTPS} {
+		if strings.HasPrefix(location, scheme) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendFile opens the local file for writing at its end, creating it if needed
+func appendFile(location string) (*os.File, error) {
+	return os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0777)
+}
+
+// atomicFile is a local file written to a temporary file, which is only renamed to
+// the file once closed. Other processes never see the file partially written
+type atomicFile struct {
+	*os.File
+	location string
+}
+
+// createAtomicFile creates the temporary file of the local file in the same directory,
+// so that it can be renamed
+func createAtomicFile(location string) (*atomicFile, error) {
+	f, err := ioutil.TempFile(filepath.Dir(location), "."+filepath.Base(location)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Chmod(0644); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &atomicFile{File: f, location: location}, nil
+}
+
+// Close closes the temporary file and renames it to the file
+func (f *atomicFile) Close() error {
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), f.location)
+}
+
+// Abort closes and removes the temporary file, leaving the file untouched
+func (f *atomicFile) Abort(err error) {
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// aborter is implemented by the writers which can discard what was written after a failure,
+// rather than publishing it when closed
+type aborter interface {
+	Abort(err error)
+}
+
+// abortFile discards the file after the failure err and returns err. Atomic files are removed,
+// remote uploads are cancelled so that no partial file is published. Other files are only closed
+func abortFile(wf io.Closer, err error) error {
+	if a, ok := wf.(aborter); ok {
+		a.Abort(err)
+	} else {
+		wf.Close()
+	}
+
+	return err
+}
+
+// splitURI splits a remote URI into its bucket (or container) and object path
+func splitURI(location string, scheme string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimPrefix(location, scheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid uri '%s', expected %s<bucket>/<path>", location, scheme)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// closers closes the wrapped reader or writer first, and then the clients it depends on
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var err error
+	for _, c := range cs {
+		if cErr := c.Close(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+
+	return err
+}
+
+// closeFunc calls the function when closed, eg. to release a context
+type closeFunc func()
+
+func (f closeFunc) Close() error {
+	f()
+	return nil
+}
+
+type remoteReader struct {
+	io.Reader
+	closers
+}
+
+// remoteWriter writes a remote file, which abort discards, if set, before the closers are closed
+type remoteWriter struct {
+	io.Writer
+	closers
+	abort func()
+}
+
+// Abort discards the remote file and closes the writer
+func (w *remoteWriter) Abort(err error) {
+	if w.abort != nil {
+		w.abort()
+	}
+
+	w.closers.Close()
+}
+
+// openGCS opens an object from Google Cloud Storage using the application default credentials
+func openGCS(ctx context.Context, location string) (io.ReadCloser, error) {
+	bucket, object, err := splitURI(location, SchemeGCS)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating gcs client")
+	}
+
+	r, err := client.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		client.Close()
+		return nil, errors.Wrapf(err, "error opening '%s'", location)
+	}
+
+	return &remoteReader{Reader: r, closers: closers{r, client}}, nil
+}
+
+// createGCS creates an object in Google Cloud Storage using the application default credentials
+func createGCS(ctx context.Context, location string) (io.WriteCloser, error) {
+	bucket, object, err := splitURI(location, SchemeGCS)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating gcs client")
+	}
+
+	// the object is created when the writer is closed, unless its context is cancelled first
+	wctx, cancel := context.WithCancel(ctx)
+	w := client.Bucket(bucket).Object(object).NewWriter(wctx)
+
+	return &remoteWriter{Writer: w, closers: closers{w, client, closeFunc(cancel)}, abort: cancel}, nil
+}
+
+// azureClient creates an Azure Blob Storage client for the account set in the
+// AZURE_STORAGE_ACCOUNT environment variable, using the default azure credential chain
+func azureClient() (*azblob.Client, error) {
+	account := os.Getenv("AZURE_STORAGE_ACCOUNT")
+	if account == "" {
+		return nil, errors.New("AZURE_STORAGE_ACCOUNT environment variable not set")
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading azure credentials")
+	}
+
+	return azblob.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", account), cred, nil)
+}
+
+// openAzure opens a blob from Azure Blob Storage
+func openAzure(ctx context.Context, location string) (io.ReadCloser, error) {
+	container, blob, err := splitURI(location, SchemeAzure)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := azureClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.DownloadStream(ctx, container, blob, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening '%s'", location)
+	}
+
+	return resp.Body, nil
+}
+
+// createAzure creates a blob in Azure Blob Storage. The content written is streamed
+// to the upload which completes when the writer is closed
+func createAzure(ctx context.Context, location string) (io.WriteCloser, error) {
+	container, blob, err := splitURI(location, SchemeAzure)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := azureClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return newPipeUpload(func(r io.Reader) error {
+		_, err := client.UploadStream(ctx, container, blob, r, nil)
+		return errors.Wrapf(err, "error uploading '%s'", location)
+	}), nil
+}
+
+// openS3 opens an object from Amazon S3, using the credentials and the region of the standard
+// AWS environment variables and shared configuration files
+func openS3(ctx context.Context, location string) (io.ReadCloser, error) {
+	bucket, key, err := splitURI(location, SchemeS3)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading aws configuration")
+	}
+
+	resp, err := s3.NewFromConfig(cfg).GetObject(ctx, &s3.GetObjectInput{Bucket: &bucket, Key: &key})
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening '%s'", location)
+	}
+
+	return resp.Body, nil
+}
+
+// createS3 creates an object in Amazon S3, using the credentials and the region of the standard
+// AWS environment variables and shared configuration files. The content written is streamed to
+// the upload which completes when the writer is closed. Aborting the writer fails the reads of
+// the uploader, which aborts the multipart upload rather than completing it
+func createS3(ctx context.Context, location string) (io.WriteCloser, error) {
+	bucket, key, err := splitURI(location, SchemeS3)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading aws configuration")
+	}
+
+	uploader := manager.NewUploader(s3.NewFromConfig(cfg))
+
+	return newPipeUpload(func(r io.Reader) error {
+		_, err := uploader.Upload(ctx, &s3.PutObjectInput{Bucket: &bucket, Key: &key, Body: r})
+		return errors.Wrapf(err, "error uploading '%s'", location)
+	}), nil
+}
+
+// openHTTP downloads the file at the url, its body being streamed as it is read
+func openHTTP(ctx context.Context, location string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error opening '%s'", location)
+	}
+
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error opening '%s': unexpected status %d", location, resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// pipeUpload is a writer streaming its content to an upload function running
+// in the background. Close waits for the upload to complete and returns its error,
+// Abort fails the upload so that the partially written content is not published
+type pipeUpload struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func newPipeUpload(upload func(r io.Reader) error) *pipeUpload {
+	pr, pw := io.Pipe()
+	pu := &pipeUpload{PipeWriter: pw, done: make(chan error, 1)}
+
+	go func() {
+		err := upload(pr)
+		pr.CloseWithError(err)
+		pu.done <- err
+	}()
+
+	return pu
+}
+
+func (pu *pipeUpload) Close() error {
+	if err := pu.PipeWriter.Close(); err != nil {
+		return err
+	}
+
+	return <-pu.done
+}
+
+// Abort fails the reads of the upload with err, eg. aborting S3 multipart uploads, and waits for it to end
+func (pu *pipeUpload) Abort(err error) {
+	pu.PipeWriter.CloseWithError(err)
+	<-pu.done
+}
+
+// sftpClient connects to the host of the sftp URI and returns the client and the remote
+// file path. The password is read from the SFTP_PASSWORD environment variable, or the
+// private key from the file in SFTP_KEY_FILE (and its passphrase from SFTP_KEY_PASSPHRASE).
+// The host key is verified against SFTP_KNOWN_HOSTS, ~/.ssh/known_hosts by default
+func sftpClient(ctx context.Context, location string) (*sftp.Client, *ssh.Client, string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "invalid uri '%s'", location)
+	}
+
+	if u.User == nil || u.User.Username() == "" || u.Path == "" {
+		return nil, nil, "", fmt.Errorf("invalid uri '%s', expected %s<user>@<host>/<path>", location, SchemeSFTP)
+	}
+
+	var auth []ssh.AuthMethod
+	if keyFile := os.Getenv("SFTP_KEY_FILE"); keyFile != "" {
+		key, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "error reading sftp private key")
+		}
+
+		var signer ssh.Signer
+		if passphrase := os.Getenv("SFTP_KEY_PASSPHRASE"); passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, nil, "", errors.Wrap(err, "error parsing sftp private key")
+		}
+
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if password := os.Getenv("SFTP_PASSWORD"); password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+
+	if len(auth) == 0 {
+		return nil, nil, "", errors.New("neither SFTP_KEY_FILE nor SFTP_PASSWORD environment variables are set")
+	}
+
+	knownHostsFile := os.Getenv("SFTP_KNOWN_HOSTS")
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, "", err
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, nil, "", errors.Wrap(err, "error loading known hosts")
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host += ":22"
+	}
+
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, nil, "", errors.Wrapf(err, "error connecting to '%s'", host)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, host, &ssh.ClientConfig{
+		User:            u.User.Username(),
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		netConn.Close()
+		return nil, nil, "", errors.Wrapf(err, "error connecting to '%s'", host)
+	}
+	conn := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, "", errors.Wrapf(err, "error starting sftp session on '%s'", host)
+	}
+
+	return client, conn, u.Path, nil
+}
+
+// openSFTP opens a file from an sftp server
+func openSFTP(ctx context.Context, location string) (io.ReadCloser, error) {
+	client, conn, path, err := sftpClient(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := client.Open(path)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return nil, errors.Wrapf(err, "error opening '%s'", location)
+	}
+
+	return &remoteReader{Reader: f, closers: closers{f, client, conn}}, nil
+}
+
+// createSFTP creates a file on an sftp server
+func createSFTP(ctx context.Context, location string) (io.WriteCloser, error) {
+	client, conn, path, err := sftpClient(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := client.Create(path)
+	if err != nil {
+		client.Close()
+		conn.Close()
+		return nil, errors.Wrapf(err, "error creating '%s'", location)
+	}
+
+	// the partial file is removed before the connection is closed
+	abort := func() {
+		f.Close()
+		client.Remove(path)
+	}
+
+	return &remoteWriter{Writer: f, closers: closers{f, client, conn}, abort: abort}, nil
+}
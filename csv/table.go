@@ -0,0 +1,106 @@
+package csv
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formats of the print operation
+const (
+	PrintCsv   = "csv"   // CSV records
+	PrintTable = "table" // a table with aligned columns, for reading
+)
+
+// writeTable writes the columns of the rows as a table with aligned columns, numbers being right
+// aligned. Cells longer than maxWidth are truncated, and at most maxRows rows are written
+func writeTable(out io.Writer, rows []Row, defs ValueDefs, cols []string, args FuncArgs) error {
+	maxWidth, err := argIntOpt(args, "maxWidth", 40)
+	if err != nil {
+		return err
+	}
+
+	maxRows, err := argIntOpt(args, "maxRows", 0)
+	if err != nil {
+		return err
+	}
+
+	if maxWidth < 1 {
+		return errors.New("maxWidth must be greater than 0")
+	}
+
+	shown := rows
+	if maxRows > 0 && len(shown) > maxRows {
+		shown = shown[:maxRows]
+	}
+
+	widths := make([]int, len(cols))
+	cells := make([][]string, 0, len(shown))
+	for i, col := range cols {
+		widths[i] = utf8.RuneCountInString(truncateCell(col, maxWidth))
+	}
+
+	for _, row := range shown {
+		rec := make([]string, len(cols))
+		for i, col := range cols {
+			rec[i] = truncateCell(valStr(row, col), maxWidth)
+			if n := utf8.RuneCountInString(rec[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		cells = append(cells, rec)
+	}
+
+	w := bufio.NewWriter(out)
+
+	writeLine := func(rec []string, header bool) {
+		for i, cell := range rec {
+			if i > 0 {
+				w.WriteString(" | ")
+			}
+
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			def := defs[cols[i]]
+			if !header && def != nil && (def.Type == TypInt || def.Type == TypFloat) {
+				w.WriteString(padding + cell)
+			} else if i < len(rec)-1 {
+				w.WriteString(cell + padding)
+			} else {
+				w.WriteString(cell)
+			}
+		}
+		w.WriteString("\n")
+	}
+
+	header := make([]string, len(cols))
+	separator := make([]string, len(cols))
+	for i, col := range cols {
+		header[i] = truncateCell(col, maxWidth)
+		separator[i] = strings.Repeat("-", widths[i])
+	}
+
+	writeLine(header, true)
+	w.WriteString(strings.Join(separator, "-+-") + "\n")
+
+	for _, rec := range cells {
+		writeLine(rec, false)
+	}
+
+	if len(shown) < len(rows) {
+		fmt.Fprintf(w, "... %d more rows\n", len(rows)-len(shown))
+	}
+
+	return w.Flush()
+}
+
+// truncateCell truncates the value to maxWidth characters, ending it with an ellipsis
+func truncateCell(val string, maxWidth int) string {
+	if utf8.RuneCountInString(val) <= maxWidth {
+		return val
+	}
+
+	return string([]rune(val)[:maxWidth-1]) + "…"
+}
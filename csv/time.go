@@ -0,0 +1,83 @@
+package csv
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeLayouts are the layouts tried in order to detect the format of date and
+// timestamp values when the column doesn't define a layout
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"20060102",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	"02 Jan 2006",
+	"2 Jan 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+// isTimeType returns whether values of the given type hold a date or a timestamp
+func isTimeType(typ string) bool {
+	return typ == TypDate || typ == TypTime
+}
+
+// parseTime parses the date or timestamp from the layout of the column if any,
+// or from the first of the common layouts matching the value otherwise.
+// Dates are truncated to the day
+func parseTime(def *ColDef, vStr string) (time.Time, error) {
+	layouts := timeLayouts
+	if def.Layout != "" {
+		layouts = []string{def.Layout}
+	}
+
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, vStr)
+		if err != nil {
+			continue
+		}
+
+		if def.Type == TypDate {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		}
+
+		return t, nil
+	}
+
+	if def.Layout != "" {
+		return time.Time{}, fmt.Errorf("not a %s with layout '%s'. vStr: '%s'", def.Type, def.Layout, vStr)
+	}
+
+	return time.Time{}, fmt.Errorf("not a %s. vStr: '%s'", def.Type, vStr)
+}
+
+// compareTime compares two optional times, an empty time being before any other.
+// It returns -1 if a is before b, 1 if a is after b, and 0 if they are equal
+func compareTime(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.Before(*b):
+		return -1
+	case a.After(*b):
+		return 1
+	}
+
+	return 0
+}
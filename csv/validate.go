@@ -0,0 +1,240 @@
+package csv
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// colTypes are the supported column types
+var colTypes = map[string]bool{TypStr: true, TypInt: true, TypFloat: true, TypBool: true, TypDate: true, TypTime: true}
+
+// Validate checks the input configuration, the column definitions and the operations against
+// the available parsers and operations without processing any data. All the problems found are returned
+func Validate(conf *InputConf, defs ValueDefs, ops []*OperationConf) []error {
+	var errs []error
+
+	// states are kept by name, the first operation always holds the original rows
+	states := map[string]bool{}
+
+	if conf != nil {
+		errs = append(errs, validateInputConf(conf)...)
+		errs = append(errs, validateInputs(conf.Inputs)...)
+
+		for name := range conf.Inputs {
+			states[name] = true
+		}
+	}
+
+	var names []string
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		errs = append(errs, validateColDef(name, defs[name], defs)...)
+	}
+
+	for opi, op := range ops {
+		if opi == 0 {
+			states[op.Name] = true
+		}
+
+		for _, state := range usedStates(op) {
+			if !states[state] {
+				errs = append(errs, fmt.Errorf("state '%s' used by operation '%s' does not exist or was never kept", state, op.Name))
+			}
+		}
+
+		if op.KeepState {
+			states[op.Name] = true
+		}
+
+		operation, ok := operations[op.Operation]
+		if !ok {
+			errs = append(errs, fmt.Errorf("operation '%s' does not exist for '%s'", op.Operation, op.Name))
+			continue
+		}
+
+		for argName, arg := range op.Args {
+			argDef, ok := operation.ArgDef[argName]
+			if !ok {
+				errs = append(errs, fmt.Errorf("unexpected argument '%s' in operation '%s' named '%s'", argName, op.Operation, op.Name))
+				continue
+			}
+
+			if err := validateOpArgType(argDef, arg); err != nil {
+				errs = append(errs, errors.Wrapf(err, "invalid type for argument '%s' in operation '%s' named '%s'", argName, op.Operation, op.Name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// usedStates returns the names of the states the operation reads, from its fromState, the
+// state argument of the operations combining rows, and the sheets of the toExcel operation
+func usedStates(op *OperationConf) []string {
+	var used []string
+	if op.FromState != "" {
+		used = append(used, op.FromState)
+	}
+
+	if state := op.Args["state"].Value; state != "" {
+		used = append(used, state)
+	}
+
+	if op.Operation == toExcelOp.Name {
+		for _, ss := range op.Args["sheets"].Values {
+			if parts := strings.SplitN(ss, "=", 2); len(parts) == 2 {
+				used = append(used, strings.TrimSpace(parts[1]))
+			}
+		}
+	}
+
+	return used
+}
+
+// validateInputConf checks the delimiter, the encoding, the error policy, the format and the query of the input
+func validateInputConf(conf *InputConf) []error {
+	var errs []error
+
+	if _, err := parseDelimiter(conf.Delimiter); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := lookupEncoding(conf.Encoding); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateErrorPolicy(conf); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateFormat(conf); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateQuery(conf); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// validateColDef checks the type of the column and its parsers
+func validateColDef(name string, def *ColDef, defs ValueDefs) []error {
+	var errs []error
+
+	if !colTypes[def.Type] {
+		errs = append(errs, fmt.Errorf("unsupported type '%s' for col '%s'", def.Type, name))
+	}
+
+	if def.Start < 0 || def.Length < 0 {
+		errs = append(errs, fmt.Errorf("start and length of col '%s' cannot be negative", name))
+	}
+
+	for _, parser := range def.Parsers {
+		if err := validateParser(parser); err != nil {
+			errs = append(errs, errors.Wrapf(err, "col '%s'", name))
+		}
+
+		for argName, arg := range parser.Args {
+			for _, col := range parserArgCols(arg) {
+				if _, ok := defs[col]; !ok {
+					errs = append(errs, fmt.Errorf("column '%s' used by argument '%s' of parser '%s' in col '%s' is not defined", col, argName, parser.Name, name))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// parserArgCols returns the columns the parser argument reads its values from
+func parserArgCols(arg ParserArg) []string {
+	cols := append([]string{}, arg.Cols...)
+	if arg.Col != "" {
+		cols = append(cols, arg.Col)
+	}
+
+	for _, val := range arg.Values {
+		cols = append(cols, parserArgCols(val)...)
+	}
+
+	return cols
+}
+
+// validateOpArgType validates that the operation argument matches the type expected by the operation
+func validateOpArgType(defType reflect.Type, arg OpArg) error {
+	if defType.Kind() == reflect.Slice {
+		if arg.Value != "" {
+			return errors.New("type must be 'values'")
+		}
+
+		return nil
+	}
+
+	if len(arg.Values) > 0 {
+		return errors.New("type must be 'value'")
+	}
+
+	switch defType.Kind() {
+	case reflect.Int:
+		if _, err := strconv.Atoi(arg.Value); arg.Value != "" && err != nil {
+			return fmt.Errorf("'%s' is not an integer", arg.Value)
+		}
+	case reflect.Bool:
+		if _, ok := parseBool(arg.Value); !ok {
+			return fmt.Errorf("'%s' is not a boolean", arg.Value)
+		}
+	}
+
+	return nil
+}
+
+// ValidateHeader reads the header of the CSV data from in and checks that all the
+// columns which are not dynamic are found in it. All the problems found are returned
+func ValidateHeader(in io.Reader, conf *InputConf, defs ValueDefs) []error {
+	if conf == nil {
+		conf = &InputConf{}
+	}
+
+	csvR, decompressor, err := newCsvReader(in, conf, defs)
+	if err != nil {
+		return []error{err}
+	}
+	defer decompressor.Close()
+
+	headerRec, err := csvR.Read()
+	if err != nil {
+		return []error{err}
+	}
+
+	header := map[string]bool{}
+	for _, h := range headerRec {
+		header[strings.TrimSpace(h)] = true
+	}
+
+	var names []string
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if def := defs[name]; def.Dynamic || name == conf.SourceCol || header[name] {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("column '%s' is not dynamic and not found in the header", name))
+	}
+
+	return errs
+}
@@ -0,0 +1,369 @@
+package csv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"runtime"
+)
+
+// Functions exported by WASM plugins
+const (
+	WasmAllocFunc    = "alloc"    // alloc(size i32) i32, allocates size bytes in the memory of the module
+	WasmDeallocFunc  = "dealloc"  // dealloc(ptr i32, size i32), optional, frees the memory given by alloc or returned
+	WasmManifestFunc = "manifest" // manifest() i64, returns the JSON manifest of the plugin
+	WasmParseFunc    = "parse"    // parse(ptr i32, size i32) i64, runs a parser of the plugin
+	WasmOperateFunc  = "operate"  // operate(ptr i32, size i32) i64, runs an operation of the plugin
+)
+
+// wasmManifest is the manifest of a WASM plugin, listing the parsers and operations it
+// exports with the types of their arguments
+type wasmManifest struct {
+	Parsers    []wasmFunc `json:"parsers"`
+	Operations []wasmFunc `json:"operations"`
+}
+
+// wasmFunc is a parser or an operation exported by a WASM plugin
+type wasmFunc struct {
+	Name string            `json:"name"`
+	Args map[string]string `json:"args"`
+}
+
+// wasmResult is the result of a parser or an operation of a WASM plugin
+type wasmResult struct {
+	Value string     `json:"value"`
+	Cols  []string   `json:"cols"`
+	Rows  [][]string `json:"rows"`
+}
+
+// wasmPlugin runs the functions of a WASM module. Instances of the module are not safe for
+// concurrent use, each call takes one from the pool or instantiates a new one
+type wasmPlugin struct {
+	filename  string
+	runtime   wazero.Runtime
+	compiled  wazero.CompiledModule
+	instances chan api.Module
+}
+
+// loadWasmPlugin loads a WASM module and registers the parsers and operations listed in its
+// manifest. The memory between csv-chef and the module is exchanged as (pointer, size) pairs,
+// packed as pointer<<32|size in the i64 results. Parsers receive {"name", "args"} and return
+// {"value"}, operations receive {"name", "args", "cols", "rows"} and return {"cols", "rows"},
+// both may return {"error"} instead
+func loadWasmPlugin(filename string) error {
+	bin, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return errors.Wrapf(err, "error opening plugin '%s'", filename)
+	}
+
+	ctx := context.Background()
+
+	p := &wasmPlugin{
+		filename:  filename,
+		runtime:   wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true)),
+		instances: make(chan api.Module, runtime.NumCPU()),
+	}
+
+	// modules built for WASI, eg. with TinyGo or GOOS=wasip1, import its functions
+	if _, err = wasi_snapshot_preview1.Instantiate(ctx, p.runtime); err != nil {
+		return errors.Wrapf(err, "error loading plugin '%s'", filename)
+	}
+
+	if p.compiled, err = p.runtime.CompileModule(ctx, bin); err != nil {
+		return errors.Wrapf(err, "error compiling plugin '%s'", filename)
+	}
+
+	for _, name := range []string{WasmAllocFunc, WasmManifestFunc} {
+		if _, ok := p.compiled.ExportedFunctions()[name]; !ok {
+			return fmt.Errorf("function '%s' is not exported by plugin '%s'", name, filename)
+		}
+	}
+
+	var manifest wasmManifest
+	if err = p.call(ctx, WasmManifestFunc, nil, &manifest); err != nil {
+		return err
+	}
+
+	if len(manifest.Parsers) == 0 && len(manifest.Operations) == 0 {
+		return fmt.Errorf("plugin '%s' exports neither parsers nor operations", filename)
+	}
+
+	var pluginParsers []ParserI
+	for _, f := range manifest.Parsers {
+		if _, ok := p.compiled.ExportedFunctions()[WasmParseFunc]; !ok {
+			return fmt.Errorf("function '%s' is not exported by plugin '%s'", WasmParseFunc, filename)
+		}
+
+		argDef, err := wasmArgDef(f, filename, false)
+		if err != nil {
+			return err
+		}
+
+		pluginParsers = append(pluginParsers, NewParser(f.Name, p.parseFunc(f.Name), argDef))
+	}
+
+	var pluginOps []Operation
+	for _, f := range manifest.Operations {
+		if _, ok := p.compiled.ExportedFunctions()[WasmOperateFunc]; !ok {
+			return fmt.Errorf("function '%s' is not exported by plugin '%s'", WasmOperateFunc, filename)
+		}
+
+		argDef, err := wasmArgDef(f, filename, true)
+		if err != nil {
+			return err
+		}
+
+		pluginOps = append(pluginOps, Operation{Name: f.Name, OpFunc: p.opFunc(f.Name, argDef), ArgDef: argDef})
+	}
+
+	if err = AddParsers(pluginParsers...); err != nil {
+		return errors.Wrapf(err, "error loading plugin '%s'", filename)
+	}
+
+	if err = AddOperations(pluginOps...); err != nil {
+		return errors.Wrapf(err, "error loading plugin '%s'", filename)
+	}
+
+	return nil
+}
+
+// wasmArgDef translates the argument types of the manifest to their go type. Parsers accept
+// the same types as the javascript and lua parsers, operations the ones of the built-in operations
+func wasmArgDef(f wasmFunc, filename string, op bool) (ArgDef, error) {
+	if f.Name == "" {
+		return nil, fmt.Errorf("parsers and operations must have a name in plugin '%s'", filename)
+	}
+
+	argDef := ArgDef{}
+	for arg, typ := range f.Args {
+		switch {
+		case typ == "string":
+			argDef[arg] = reflect.TypeOf("")
+		case typ == "array" && op:
+			argDef[arg] = reflect.TypeOf([]string{})
+		case typ == "array":
+			argDef[arg] = reflect.TypeOf([]interface{}{})
+		case typ == "object" && !op:
+			argDef[arg] = reflect.TypeOf(map[string]interface{}{})
+		case typ == "int" && op:
+			argDef[arg] = reflect.TypeOf(1)
+		case typ == "bool" && op:
+			argDef[arg] = reflect.TypeOf(true)
+		default:
+			return nil, fmt.Errorf("type '%s' of argument '%s' of '%s' is not supported in plugin '%s'", typ, arg, f.Name, filename)
+		}
+	}
+
+	return argDef, nil
+}
+
+// parseFunc returns the parse function running the parser of the plugin
+func (p *wasmPlugin) parseFunc(name string) ParseFunc {
+	return func(ctx context.Context, args FuncArgs) (string, error) {
+		var res wasmResult
+		if err := p.call(ctx, WasmParseFunc, map[string]interface{}{"name": name, "args": args}, &res); err != nil {
+			return "", err
+		}
+
+		return res.Value, nil
+	}
+}
+
+// opFunc returns the operation function running the operation of the plugin. The rows are
+// passed as strings, the columns returned by the plugin which are not defined yet are strings
+func (p *wasmPlugin) opFunc(name string, argDef ArgDef) OpFunc {
+	return func(ctx context.Context, env *OpEnv, rows *[]Row, defs ValueDefs, args FuncArgs) ([]Row, ValueDefs, error) {
+		// op arguments are given as strings, they are converted to their type first
+		opArgs := map[string]interface{}{}
+		for arg, typ := range argDef {
+			if _, ok := args[arg]; !ok {
+				continue
+			}
+
+			var err error
+			switch typ.Kind() {
+			case reflect.Int:
+				opArgs[arg], err = argInt(args, arg)
+			case reflect.Bool:
+				opArgs[arg], err = argBool(args, arg)
+			default:
+				opArgs[arg] = args[arg]
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		cols := sortedCols(defs)
+
+		records := make([][]string, 0, len(*rows))
+		for _, row := range *rows {
+			record := make([]string, 0, len(cols))
+			for _, col := range cols {
+				record = append(record, valStr(row, col))
+			}
+			records = append(records, record)
+		}
+
+		var res wasmResult
+		input := map[string]interface{}{"name": name, "args": opArgs, "cols": cols, "rows": records}
+		if err := p.call(ctx, WasmOperateFunc, input, &res); err != nil {
+			return nil, nil, err
+		}
+
+		outDefs := ValueDefs{}
+		for _, col := range res.Cols {
+			if def, ok := defs[col]; ok {
+				outDefs[col] = def
+				continue
+			}
+			outDefs[col] = &ColDef{Name: col, Type: TypStr, Dynamic: true}
+		}
+
+		outRows := make([]Row, 0, len(res.Rows))
+		for i, record := range res.Rows {
+			if len(record) != len(res.Cols) {
+				return nil, nil, fmt.Errorf("row %d returned by operation '%s' has %d values for %d columns", i+1, name, len(record), len(res.Cols))
+			}
+
+			row := Row{}
+			for j, col := range res.Cols {
+				var val RowValue = emptyValue(outDefs[col])
+				if record[j] != "" {
+					var err error
+					if val, err = NewValue(outDefs[col], record[j]); err != nil {
+						return nil, nil, errors.Wrapf(err, "error in column '%s' of row %d returned by operation '%s'", col, i+1, name)
+					}
+				}
+				row[col] = val
+			}
+
+			outRows = append(outRows, row)
+		}
+
+		return outRows, outDefs, nil
+	}
+}
+
+// call runs the function of the plugin with the JSON encoded input, if any, and decodes its
+// JSON result into output. An error is returned if the result has its error set
+func (p *wasmPlugin) call(ctx context.Context, name string, input interface{}, output interface{}) (err error) {
+	mod, err := p.instance(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		// the instance may be broken after a failed call, it is not reused
+		if err != nil {
+			mod.Close(context.Background())
+			return
+		}
+
+		select {
+		case p.instances <- mod:
+		default:
+			mod.Close(context.Background())
+		}
+	}()
+
+	var params []uint64
+	if input != nil {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding the input of '%s' in plugin '%s'", name, p.filename)
+		}
+
+		ptr, err := p.write(ctx, mod, data)
+		if err != nil {
+			return err
+		}
+		defer p.dealloc(ctx, mod, ptr, uint32(len(data)))
+
+		params = []uint64{uint64(ptr), uint64(len(data))}
+	}
+
+	results, err := mod.ExportedFunction(name).Call(ctx, params...)
+	if err != nil {
+		return errors.Wrapf(err, "error calling '%s' in plugin '%s'", name, p.filename)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("function '%s' must return a single i64 in plugin '%s'", name, p.filename)
+	}
+
+	ptr, size := uint32(results[0]>>32), uint32(results[0])
+	data, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return fmt.Errorf("result of '%s' is out of the memory of plugin '%s'", name, p.filename)
+	}
+
+	var res struct {
+		Error string `json:"error"`
+	}
+
+	if err = json.Unmarshal(data, &res); err == nil {
+		err = json.Unmarshal(data, output)
+	}
+	p.dealloc(ctx, mod, ptr, size)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding the result of '%s' in plugin '%s'", name, p.filename)
+	}
+
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+
+	return nil
+}
+
+// instance returns an instance of the module from the pool, or a new one if none is available
+func (p *wasmPlugin) instance(ctx context.Context) (api.Module, error) {
+	select {
+	case mod := <-p.instances:
+		return mod, nil
+	default:
+	}
+
+	// instances are anonymous so that the module can be instantiated several times. Reactor
+	// modules are initialised by '_initialize', '_start' is not called
+	conf := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize").WithStderr(os.Stderr)
+
+	mod, err := p.runtime.InstantiateModule(ctx, p.compiled, conf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error instantiating plugin '%s'", p.filename)
+	}
+
+	return mod, nil
+}
+
+// write copies the data into the memory of the module, allocated by its alloc function
+func (p *wasmPlugin) write(ctx context.Context, mod api.Module, data []byte) (uint32, error) {
+	results, err := mod.ExportedFunction(WasmAllocFunc).Call(ctx, uint64(len(data)))
+	if err != nil {
+		return 0, errors.Wrapf(err, "error allocating memory in plugin '%s'", p.filename)
+	}
+	if len(results) != 1 {
+		return 0, fmt.Errorf("function '%s' must return a single i32 in plugin '%s'", WasmAllocFunc, p.filename)
+	}
+
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("memory allocated by '%s' is out of the memory of plugin '%s'", WasmAllocFunc, p.filename)
+	}
+
+	return ptr, nil
+}
+
+// dealloc frees the memory of the module if it exports a dealloc function
+func (p *wasmPlugin) dealloc(ctx context.Context, mod api.Module, ptr uint32, size uint32) {
+	if f := mod.ExportedFunction(WasmDeallocFunc); f != nil {
+		f.Call(ctx, uint64(ptr), uint64(size))
+	}
+}
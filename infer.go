@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"github.com/nicored/csv-chef/csv"
+	"gopkg.in/yaml.v2"
+	"io"
+)
+
+// inferredCol is the column definition written in the generated configuration
+type inferredCol struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Layout string `yaml:"layout,omitempty"`
+}
+
+// inferredOp is the operation written in the generated configuration
+type inferredOp struct {
+	Name      string                 `yaml:"name"`
+	Operation string                 `yaml:"operation"`
+	Args      map[string]inferredArg `yaml:"args"`
+}
+
+// inferredArg is the argument of the operation written in the generated configuration
+type inferredArg struct {
+	Values []string `yaml:"values"`
+}
+
+// inferredConfig is the starter configuration generated from a sample CSV file
+type inferredConfig struct {
+	Cols       []inferredCol `yaml:"cols"`
+	Operations []inferredOp  `yaml:"operations"`
+}
+
+// infer samples the given number of rows of the CSV file, guesses the type of its
+// columns, and writes a starter configuration printing all columns to w
+func infer(csvFile string, sampleSize int, w io.Writer) error {
+	in, err := csv.OpenInput(context.Background(), csvFile)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	defs, err := csv.InferDefs(in, nil, sampleSize)
+	if err != nil {
+		return err
+	}
+
+	conf := inferredConfig{}
+	var names []string
+
+	for _, def := range defs {
+		conf.Cols = append(conf.Cols, inferredCol{Name: def.Name, Type: def.Type, Layout: def.Layout})
+		names = append(names, def.Name)
+	}
+
+	conf.Operations = append(conf.Operations, inferredOp{
+		Name:      "print_all",
+		Operation: "print",
+		Args:      map[string]inferredArg{"cols": {Values: names}},
+	})
+
+	out, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
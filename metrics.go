@@ -0,0 +1,53 @@
+package main
+
+import (
+	"github.com/nicored/csv-chef/csv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+// MetricsConf is the configuration exposing the run metrics to Prometheus
+type MetricsConf struct {
+	Listen      string `yaml:"listen"`      // address serving the /metrics endpoint while running, eg. ':9100'
+	PushGateway string `yaml:"pushGateway"` // push gateway url the metrics are pushed to once the run completes
+	Job         string `yaml:"job"`         // job name used when pushing, 'csv-chef' by default
+}
+
+// serveMetrics exposes the /metrics endpoint in the background if configured
+func (d *Data) serveMetrics() {
+	if d.Config.Metrics == nil || d.Config.Metrics.Listen == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(csv.Metrics, promhttp.HandlerOpts{}))
+
+	go func() {
+		if err := http.ListenAndServe(d.Config.Metrics.Listen, mux); err != nil {
+			logrus.Errorf("metrics endpoint stopped: %s", err)
+		}
+	}()
+}
+
+// pushMetrics pushes the metrics to the push gateway if configured
+func (d *Data) pushMetrics(summary csv.RunSummary) {
+	if d.Config.Metrics == nil || d.Config.Metrics.PushGateway == "" {
+		return
+	}
+
+	job := d.Config.Metrics.Job
+	if job == "" {
+		job = "csv-chef"
+	}
+
+	pusher := push.New(d.Config.Metrics.PushGateway, job).Gatherer(csv.Metrics)
+	if d.Config.Name != "" {
+		pusher = pusher.Grouping("pipeline", d.Config.Name)
+	}
+
+	if err := pusher.Push(); err != nil {
+		logrus.Warnf("failed pushing metrics: %s", err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/nicored/csv-chef/csv"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	NotifySlack   = "slack"
+	NotifyWebhook = "webhook"
+
+	NotifyOnSuccess = "success"
+	NotifyOnFailure = "failure"
+)
+
+// Notification is the configuration of a notification sent at the end of a run
+type Notification struct {
+	Type string   `yaml:"type"` // either slack or webhook
+	URL  string   `yaml:"url"`
+	On   []string `yaml:"on"` // success and/or failure, both by default
+}
+
+// NotificationPayload is the JSON body sent to generic webhooks
+type NotificationPayload struct {
+	Pipeline   string  `json:"pipeline"`
+	File       string  `json:"file"`
+	Success    bool    `json:"success"`
+	Duration   float64 `json:"durationSeconds"`
+	RowsRead   int     `json:"rowsRead"`
+	Operations int     `json:"operations"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// validateNotifications checks the notifications configuration
+func (d *Data) validateNotifications() error {
+	for i, n := range d.Config.Notifications {
+		if n.Type != NotifySlack && n.Type != NotifyWebhook {
+			return fmt.Errorf("notification %d: type must either be '%s' or '%s'", i, NotifySlack, NotifyWebhook)
+		}
+
+		if n.URL == "" {
+			return fmt.Errorf("notification %d: url is required", i)
+		}
+
+		for _, on := range n.On {
+			if on != NotifyOnSuccess && on != NotifyOnFailure {
+				return fmt.Errorf("notification %d: on must either be '%s' or '%s'", i, NotifyOnSuccess, NotifyOnFailure)
+			}
+		}
+	}
+
+	return nil
+}
+
+// notify sends the configured notifications for the completed run.
+// Failing to notify is logged but never fails the run
+func (d *Data) notify(summary csv.RunSummary) {
+	payload := NotificationPayload{
+		Pipeline:   d.Config.Name,
+		File:       summary.File,
+		Success:    summary.Err == nil,
+		Duration:   summary.Duration.Seconds(),
+		RowsRead:   summary.RowsRead,
+		Operations: summary.Operations,
+	}
+	if summary.Err != nil {
+		payload.Error = summary.Err.Error()
+	}
+
+	for _, n := range d.Config.Notifications {
+		if !n.fires(payload.Success) {
+			continue
+		}
+
+		var body interface{} = payload
+		if n.Type == NotifySlack {
+			body = map[string]string{"text": payload.slackText()}
+		}
+
+		if err := postJSON(n.URL, body); err != nil {
+			logrus.Warnf("failed sending %s notification: %s", n.Type, err)
+		}
+	}
+}
+
+// fires tells whether the notification is sent for a run with the given outcome
+func (n *Notification) fires(success bool) bool {
+	if len(n.On) == 0 {
+		return true
+	}
+
+	for _, on := range n.On {
+		if (on == NotifyOnSuccess && success) || (on == NotifyOnFailure && !success) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// slackText returns the summary of the run formatted for a slack message
+func (p NotificationPayload) slackText() string {
+	name := p.Pipeline
+	if name == "" {
+		name = p.File
+	}
+
+	if !p.Success {
+		return fmt.Sprintf(":x: csv-chef run '%s' failed after %.1fs (%d rows read, %d operations): %s",
+			name, p.Duration, p.RowsRead, p.Operations, p.Error)
+	}
+
+	return fmt.Sprintf(":white_check_mark: csv-chef run '%s' succeeded in %.1fs (%d rows read, %d operations)",
+		name, p.Duration, p.RowsRead, p.Operations)
+}
+
+func postJSON(url string, body interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/nicored/csv-chef/csv"
+	"io"
+	"time"
+)
+
+// validate checks the configuration, and the header of the CSV file if not empty, without
+// processing any data. All the problems found are written to w
+func validate(configFile string, csvFile string, w io.Writer) error {
+	d := &Data{configFile: configFile, csvFile: csvFile}
+
+	// loading the parsers the configuration refers to, failing to do so is fatal
+	if err := d.parseConfig(); err != nil {
+		return err
+	}
+
+	errs := csv.Validate(d.Config.Input, d.ValueDefs, d.Config.Operations)
+
+	if d.Config.Timeout != "" {
+		if _, err := time.ParseDuration(d.Config.Timeout); err != nil {
+			errs = append(errs, fmt.Errorf("invalid timeout '%s'", d.Config.Timeout))
+		}
+	}
+
+	// the header can only be read if the input configuration is valid
+	if d.csvFile != "" && len(csv.Validate(d.Config.Input, nil, nil)) == 0 {
+		in, err := csv.OpenInput(context.Background(), d.csvFile)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		errs = append(errs, csv.ValidateHeader(in, d.Config.Input, d.ValueDefs)...)
+	}
+
+	for _, err := range errs {
+		fmt.Fprintln(w, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d problems found in '%s'", len(errs), d.configFile)
+	}
+
+	fmt.Fprintf(w, "'%s' is valid\n", d.configFile)
+	return nil
+}